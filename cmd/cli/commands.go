@@ -0,0 +1,446 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/config"
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/domain"
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/infrastructure/persistance/postgresql"
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/infrastructure/persistance/sqlite"
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/infrastructure/persistance/storage"
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/interface/cli"
+)
+
+// openStorage loads config registered by loader and connects repositories.
+func openStorage(loader *config.Loader, out io.Writer) (*storage.Storage, *slog.Logger, error) {
+	cfg, err := loader.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	level, _ := cfg.Level()
+	logger := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{
+		Level: level,
+	}))
+
+	st, err := storage.Open(logger, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return st, logger, nil
+}
+
+// newLoader makes config loader for commands which use storage. Logs are
+// written among game output, so only errors are shown by default.
+func newLoader(fs *flag.FlagSet) *config.Loader {
+	loader := config.NewLoader(os.Getenv, config.SectionDatabase)
+	loader.Defaults.LogLevel = "error"
+	loader.RegisterFlags(fs)
+	return loader
+}
+
+// nobody plays games which only are shown, not played.
+var nobody = &domain.Player{Nickname: "nobody"}
+
+// gameFlags describe the board shared by play and solve.
+type gameFlags struct {
+	pegs  uint
+	disks uint
+	seed  *int64
+	start string
+	goal  string
+	rules string
+}
+
+func (f *gameFlags) register(fs *flag.FlagSet) {
+	fs.UintVar(&f.pegs, "pegs", 3, "number of pegs")
+	fs.UintVar(&f.disks, "disks", 5, "number of disks")
+	fs.Func("seed", "seed of the random layout, the same seed gives the same game", func(s string) error {
+		seed, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		f.seed = &seed
+		return nil
+	})
+	fs.StringVar(&f.start, "start", "", "start layout: random, classic or distance:N")
+	fs.StringVar(&f.goal, "goal", "", "goal: any, peg:N or layout:...")
+	fs.StringVar(&f.rules, "ruleset", "", fmt.Sprintf("rules of the game: %s", strings.Join(domain.Rulesets(), ", ")))
+}
+
+func (f *gameFlags) options() ([]domain.GameOption, error) {
+	var opts []domain.GameOption
+	if f.seed != nil {
+		opts = append(opts, domain.WithSeed(*f.seed))
+	}
+
+	start, err := domain.ParseStartLayout(f.start)
+	if err != nil {
+		return nil, err
+	}
+	if start != nil {
+		opts = append(opts, domain.WithStartLayout(start))
+	}
+
+	if f.goal != "" {
+		goal, err := domain.ParseGoal(f.goal)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, domain.WithGoal(goal))
+	}
+
+	rules, err := domain.ParseRuleset(f.rules)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, domain.WithRuleset(rules))
+
+	return opts, nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s:\n", name)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// runPlay starts the interactive game.
+func runPlay(args []string) error {
+	fs := newFlagSet("play")
+	var game gameFlags
+	game.register(fs)
+	loader := newLoader(fs)
+	fullScreen := fs.Bool("tui", false, "start in full-screen mode")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts, err := game.options()
+	if err != nil {
+		return err
+	}
+	// The first game is dealt before login, so typos are reported early. It
+	// is handed to the player then, a distance start is not searched again.
+	first, err := domain.NewGame(game.pegs, game.disks, nobody, domain.DefaultColorPicker(), opts...)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	st, logger, err := openStorage(loader, out)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	// A seed from flags is used only for the first game, 'n' deals new ones.
+	if game.seed != nil {
+		game.seed = nil
+		if opts, err = game.options(); err != nil {
+			return err
+		}
+	}
+
+	input := cli.NewInput(os.Stdin)
+	deps := CliDependencies{
+		logger:     logger,
+		out:        out,
+		input:      input,
+		scanner:    bufio.NewScanner(input),
+		playerRepo: st.Players,
+		recordRepo: st.Records,
+		renderer:   cli.NewRenderer(cli.DetectColorMode(os.Getenv), terminalWidth()),
+		pegs:       game.pegs,
+		disks:      game.disks,
+		gameOpts:   opts,
+		first:      first,
+	}
+
+	return play(&deps, *fullScreen)
+}
+
+// runSolve prints the board and moves which win it.
+func runSolve(args []string) error {
+	fs := newFlagSet("solve")
+	var game gameFlags
+	game.register(fs)
+	exact := fs.Bool("exact", false, "search for the shortest solution, slow for big boards")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts, err := game.options()
+	if err != nil {
+		return err
+	}
+
+	g, err := domain.NewGame(game.pegs, game.disks, nobody, domain.DefaultColorPicker(), opts...)
+	if err != nil {
+		return err
+	}
+
+	var moves []domain.Move
+	if *exact {
+		moves, err = domain.SolveExact(g, 0)
+	} else {
+		moves, err = domain.Solve(g)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot solve: %w", err)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	fmt.Fprintf(out, "Game seed: %d\nGoal: %s\nRules: %s\n", g.Seed, g.Goal, g.Rules.Name())
+	cli.NewRenderer(cli.DetectColorMode(os.Getenv), terminalWidth()).Render(out, g)
+	fmt.Fprintf(out, "Moves: %d\n", len(moves))
+	for _, m := range moves {
+		fmt.Fprintf(out, "m %d %d\n", m.From, m.To)
+	}
+
+	return nil
+}
+
+// runPlayers lists registered players.
+func runPlayers(args []string) error {
+	fs := newFlagSet("players")
+	loader := newLoader(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	st, _, err := openStorage(loader, out)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	players, err := st.Players.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot get players: %w", err)
+	}
+
+	for _, p := range players {
+		PrintPlayerInfo(out, p)
+	}
+	return nil
+}
+
+// runRecords prints the leaderboard.
+func runRecords(args []string) error {
+	fs := newFlagSet("records")
+	loader := newLoader(fs)
+	var filter domain.RecordFilter
+	fs.IntVar(&filter.Pegs, "pegs", 0, "only boards with this many pegs, 0 for any")
+	fs.IntVar(&filter.Disks, "disks", 0, "only boards with this many disks, 0 for any")
+	fs.StringVar(&filter.Ruleset, "ruleset", domain.StandardRulesetName, "rules of the games, each one has its own records")
+	fs.StringVar(&filter.Goal, "goal", "any", "goal of the games: any, peg:N or layout:...")
+	fs.IntVar(&filter.Limit, "limit", 0, "number of records, 0 for default")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if _, err := domain.ParseRuleset(filter.Ruleset); err != nil {
+		return err
+	}
+	goal, err := domain.ParseGoal(filter.Goal)
+	if err != nil {
+		return err
+	}
+	filter.Goal = goal.String()
+
+	out := os.Stdout
+	st, _, err := openStorage(loader, out)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	records, err := st.Records.GetTop(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("cannot get records: %w", err)
+	}
+
+	if len(records) == 0 {
+		fmt.Fprintln(out, "No records yet")
+		return nil
+	}
+
+	PrintRecords(out, records)
+	return nil
+}
+
+// runReplay shows a game saved by 's FILE' move by move.
+func runReplay(args []string) error {
+	fs := newFlagSet("replay")
+	delay := fs.Duration("delay", 500*time.Millisecond, "pause between moves")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("replay needs exactly one file, got %d", fs.NArg())
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var rec domain.Recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return fmt.Errorf("cannot parse %s: %w", fs.Arg(0), err)
+	}
+
+	out := os.Stdout
+	renderer := cli.NewRenderer(cli.DetectColorMode(os.Getenv), terminalWidth())
+
+	start := rec
+	start.Moves = nil
+	g, err := domain.Replay(start, nobody, domain.DefaultColorPicker())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Game seed: %d\nGoal: %s\nRules: %s\n", g.Seed, g.Goal, g.Rules.Name())
+	renderer.Render(out, g)
+
+	g, err = domain.ReplayEach(rec, nobody, domain.DefaultColorPicker(), func(g *domain.Game, m domain.LoggedMove) {
+		time.Sleep(*delay)
+		fmt.Fprintf(out, "\n#%d %s: disk %d from %d to %d\n", g.Step, m.Kind, m.DiskSize, m.From, m.To)
+		renderer.Render(out, g)
+	})
+	if err != nil {
+		return err
+	}
+
+	if g.IsWon() {
+		fmt.Fprintf(out, "Won in %d steps (net %d)\n", g.Step, g.NetSteps())
+	}
+	return nil
+}
+
+// runMigrate changes the database schema: 'migrate up', 'migrate down [N|all]'
+// or 'migrate version'.
+func runMigrate(args []string) error {
+	fs := newFlagSet("migrate")
+	loader := config.NewLoader(os.Getenv, config.SectionDatabase)
+	loader.RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		return err
+	}
+
+	action, rest := fs.Arg(0), fs.Args()[min(1, fs.NArg()):]
+	steps := 1
+	switch {
+	case action == "down" && len(rest) == 1 && rest[0] == "all":
+		steps = 0
+	case action == "down" && len(rest) == 1:
+		steps, err = strconv.Atoi(rest[0])
+		if err != nil || steps <= 0 {
+			return fmt.Errorf("number of migrations should be positive, got %q", rest[0])
+		}
+	case (action == "up" || action == "down" || action == "version") && len(rest) == 0:
+	default:
+		return fmt.Errorf("usage: migrate [FLAGS] up|down [N|all]|version")
+	}
+
+	level, _ := cfg.Level()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: level,
+	}))
+
+	mg, closeDB, err := openMigrator(logger, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	defer mg.Close()
+
+	switch action {
+	case "up":
+		err = mg.Up()
+	case "down":
+		err = mg.Down(steps)
+	}
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := mg.Version()
+	if errors.Is(err, postgresql.ErrNoMigrations) || errors.Is(err, sqlite.ErrNoMigrations) {
+		fmt.Println("No migrations are applied")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Schema version: %d\n", version)
+	if dirty {
+		fmt.Println("Last migration has failed, the schema needs a manual fix")
+	}
+	return nil
+}
+
+type migrator interface {
+	Up() error
+	Down(steps int) error
+	Version() (uint, bool, error)
+	Close() error
+}
+
+// openMigrator connects to the database of configured storage.
+func openMigrator(logger *slog.Logger, cfg *config.Config) (migrator, func() error, error) {
+	var db *sql.DB
+	var err error
+	switch cfg.Storage {
+	case config.StoragePostgres:
+		db, err = postgresql.Open(logger, cfg.Database)
+	case config.StorageSQLite:
+		db, err = sqlite.Open(logger, cfg.Database)
+	default:
+		return nil, nil, fmt.Errorf("%s storage has no migrations", cfg.Storage)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var mg migrator
+	if cfg.Storage == config.StoragePostgres {
+		mg, err = postgresql.NewMigrator(logger, db, cfg.Database.Migrations)
+	} else {
+		mg, err = sqlite.NewMigrator(logger, db, cfg.Database.Migrations)
+	}
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return mg, db.Close, nil
+}
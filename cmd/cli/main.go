@@ -2,21 +2,24 @@ package main
 
 import (
 	"bufio"
+	"cmp"
 	"context"
-	"database/sql"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"log/slog"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/AnruKitakaze/tower-of-hanoi/internal/domain"
-	"github.com/AnruKitakaze/tower-of-hanoi/internal/infrastructure/persistance/postgresql"
 	"github.com/AnruKitakaze/tower-of-hanoi/internal/interface/cli"
+	"golang.org/x/term"
 )
 
 // TODO: Should put it into internal/.../game_handlers.go or something
@@ -34,79 +37,32 @@ const (
 	White   TerminalColor = "\033[37m"
 )
 
-func PrintPeg(peg *domain.Peg) {
-	d := peg.TopDisk
-	if d == nil {
-		fmt.Println(Red, "empty peg", Reset)
-		return
-	}
-	fmt.Print(Reset)
-	for d != nil {
-		fmt.Printf("%d ", d.Size)
-		d = d.Next
-	}
-	fmt.Println()
-}
-
-func PrintField(field *domain.Game) {
-	t := make([][]uint, len(field.Pegs))
-	for i := range len(t) {
-		t[i] = make([]uint, field.TotalDisks)
-	}
-
-	var j int
-	for i, p := range field.Pegs {
-		d := p.TopDisk
-		for d != nil {
-
-			t[i][field.TotalDisks-j-1] = d.Size
-			j++
-
-			d = d.Next
-		}
-		j = 0
-	}
-
-	for j := field.TotalDisks - 1; j >= 0; j-- {
-		for i := range len(field.Pegs) {
-			if t[i][j] != 0 {
-				fmt.Printf("%d\t", t[i][j])
-			} else {
-				fmt.Printf("|\t")
-			}
-		}
-		fmt.Println()
-	}
-
-	for i := range len(field.Pegs) {
-		fmt.Printf("Peg #%d\t", i)
-	}
-	fmt.Println()
-}
-
-func play(d *CliDependencies) {
+func play(d *CliDependencies, fullScreen bool) error {
 	if d.playerRepo == nil {
 		panic("player repository is not connected")
 	}
 
 	player, err := handleLogin(d)
 	if err != nil {
-		log.Fatal("play: unhandled error: %w", err)
-	}
-
-	field, err := domain.NewGame(3, 5, player, domain.DefaultColorPicker())
-	if err != nil {
-		panic(err)
+		return fmt.Errorf("play: cannot login: %w", err)
 	}
 
 	var input []string
 
 	fmt.Fprintln(d.out, cli.Welcome)
-	PrintField(field)
+	field := d.first
+	field.Player = player
+	announceGame(d.out, field)
+	if fullScreen {
+		handleFullScreen(d, field)
+	}
+	d.renderer.Render(d.out, field)
 
 	for {
 		fmt.Println(Reset)
-		d.scanner.Scan()
+		if !d.scanner.Scan() {
+			return d.scanner.Err()
+		}
 		input = strings.Split(d.scanner.Text(), " ")
 		if len(input) == 1 && input[0] == "" || len(input) == 0 {
 			fmt.Println(Red)
@@ -114,7 +70,19 @@ func play(d *CliDependencies) {
 			continue
 		} else if strings.ToLower(input[0]) == "m" {
 			fmt.Println(Red)
-			handleMove(d.out, input, field)
+			handleMove(d, input, field)
+		} else if strings.ToLower(input[0]) == "u" {
+			fmt.Println(Red)
+			handleUndo(d.out, field)
+		} else if strings.ToLower(input[0]) == "y" {
+			fmt.Println(Red)
+			handleRedo(d, field)
+		} else if input[0] == "?" {
+			fmt.Println(Cyan)
+			handleHint(d.out, field)
+			continue
+		} else if strings.ToLower(input[0]) == "t" {
+			handleFullScreen(d, field)
 		} else if strings.ToLower(input[0]) == "h" {
 			fmt.Println(Green)
 			fmt.Fprintln(d.out, cli.Manual)
@@ -122,7 +90,7 @@ func play(d *CliDependencies) {
 		} else if strings.ToLower(input[0]) == "q" {
 			fmt.Println(Green)
 			fmt.Fprintln(d.out, cli.Bye)
-			return
+			return nil
 		} else if strings.ToLower(input[0]) == "p" {
 			fmt.Println(Yellow)
 			handleGetAllPlayers(d)
@@ -132,30 +100,78 @@ func play(d *CliDependencies) {
 			p, err := handleLogin(d)
 			if err != nil {
 				fmt.Println(fmt.Errorf("failed to login: %w", err))
+			} else if f, err := startGame(d, p); err != nil {
+				fmt.Fprintln(d.out, err)
 			} else {
-				player = p
-
-				// TODO: to func
-				field, err = domain.NewGame(3, 5, player, domain.DefaultColorPicker())
-				if err != nil {
-					panic(err)
-				}
+				player, field = p, f
 			}
 		} else if strings.ToLower(input[0]) == "r" {
 			fmt.Println(Blue)
-			fmt.Fprintln(d.out, "Records table is in development")
-			// handleRecords(d.out, input, field)
+			handleRecords(d, input, field)
 			continue
 		} else if strings.ToLower(input[0]) == "n" {
-			// TODO: to func
-			field, err = domain.NewGame(3, 5, player, domain.DefaultColorPicker())
+			opts, err := newGameOptions(input[1:], field)
 			if err != nil {
-				panic(err)
+				fmt.Println(Red)
+				fmt.Fprintf(d.out, "Seems like %v\n", err)
+				continue
+			}
+			if f, err := startGame(d, player, opts...); err != nil {
+				fmt.Fprintln(d.out, err)
+			} else {
+				field = f
 			}
+		} else if strings.ToLower(input[0]) == "s" {
+			fmt.Println(Yellow)
+			handleSave(d.out, input, field)
+			continue
 		}
 
 		fmt.Print(Reset)
-		PrintField(field)
+		d.renderer.Render(d.out, field)
+	}
+}
+
+// newGameOptions reads 'n [SEED] [RULESET]' in any order. The new game keeps
+// rules of the current one unless others are given.
+func newGameOptions(args []string, field *domain.Game) ([]domain.GameOption, error) {
+	opts := []domain.GameOption{domain.WithRuleset(field.Rules)}
+	for _, arg := range args {
+		if arg == "" {
+			continue
+		}
+		if seed, err := strconv.ParseInt(arg, 10, 64); err == nil {
+			opts = append(opts, domain.WithSeed(seed))
+			continue
+		}
+
+		rules, err := domain.ParseRuleset(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%q is neither a seed nor rules: %w", arg, err)
+		}
+		opts = append(opts, domain.WithRuleset(rules))
+	}
+	return opts, nil
+}
+
+// startGame creates a new game of the board chosen by flags and announces it.
+func startGame(d *CliDependencies, player *domain.Player, opts ...domain.GameOption) (*domain.Game, error) {
+	opts = append(slices.Clone(d.gameOpts), opts...)
+	field, err := domain.NewGame(d.pegs, d.disks, player, domain.DefaultColorPicker(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot start game: %w", err)
+	}
+
+	announceGame(d.out, field)
+	return field, nil
+}
+
+// announceGame tells seed of the game, so the same layout can be played
+// again with 'n SEED'.
+func announceGame(out io.Writer, field *domain.Game) {
+	fmt.Fprintf(out, "Game seed: %d\nRules: %s\n", field.Seed, field.Rules.Name())
+	if par, err := domain.Par(field); err == nil {
+		fmt.Fprintf(out, "Par: %d moves\n", par)
 	}
 }
 
@@ -183,7 +199,9 @@ func handleLogin(d *CliDependencies) (*domain.Player, error) {
 	for {
 		fmt.Print(Reset)
 		fmt.Print("Enter your name: ")
-		d.scanner.Scan()
+		if !d.scanner.Scan() {
+			return nil, cmp.Or(d.scanner.Err(), io.EOF)
+		}
 		nickname := d.scanner.Text()
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -195,7 +213,9 @@ func handleLogin(d *CliDependencies) (*domain.Player, error) {
 			}
 
 			fmt.Fprintf(d.out, "Player with name %s does not exist. Want to create? (y/n) ", nickname)
-			d.scanner.Scan()
+			if !d.scanner.Scan() {
+				return nil, cmp.Or(d.scanner.Err(), io.EOF)
+			}
 			i := d.scanner.Text()
 
 			saidNo := len(i) == 0 || (i != "y" && i != "д")
@@ -217,7 +237,8 @@ func handleLogin(d *CliDependencies) (*domain.Player, error) {
 	}
 }
 
-func handleMove(out io.Writer, input []string, field *domain.Game) {
+func handleMove(d *CliDependencies, input []string, field *domain.Game) {
+	out := d.out
 	if len(input) < 3 {
 		fmt.Fprint(out, "Swap command require two peg numbers\n")
 		return
@@ -243,59 +264,247 @@ func handleMove(out io.Writer, input []string, field *domain.Game) {
 		fmt.Fprintf(out, "X and Y should be in a range [0, %d)\n", len(field.Pegs))
 		return
 	}
+	wasWon := field.IsWon()
 	err = field.MoveDisk(x, y)
 	if err != nil {
 		fmt.Fprintf(out, "cannot move disk: %v\n", err.Error())
 	}
 
-	if field.IsWon() {
-		fmt.Fprintf(out, "Congratulations, %s! You've won! Steps: %d\n", field.Player.Nickname, field.Step)
+	if !wasWon && field.IsWon() {
+		handleWin(d, field)
 	}
 }
 
-type CliDependencies struct {
-	logger     *slog.Logger
-	out        io.Writer
-	scanner    *bufio.Scanner
-	playerRepo domain.PlayerRepository
+// handleWin congratulates the player and saves the record. It is called only
+// when the game becomes won, so walking around the won position does not
+// flood the records table.
+func handleWin(d *CliDependencies, field *domain.Game) {
+	fmt.Fprintf(d.out, "Congratulations, %s! You've won! Steps: %d (net %d)\n", field.Player.Nickname, field.Step, field.NetSteps())
+	if par, err := domain.Par(field); err == nil {
+		fmt.Fprintf(d.out, "Par: %d\n", par)
+	}
+	if field.HintsUsed > 0 {
+		fmt.Fprintf(d.out, "Hints used: %d\n", field.HintsUsed)
+	}
+
+	if err := saveRecord(d, field); err != nil {
+		fmt.Fprintln(d.out, err)
+	}
 }
 
-func main() {
-	scanner := bufio.NewScanner(os.Stdin)
-	out := os.Stdout
+func saveRecord(d *CliDependencies, field *domain.Game) error {
+	if d.recordRepo == nil {
+		return nil
+	}
 
-	logger := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{
-		Level: slog.LevelError,
-	}))
+	rec, err := domain.NewRecord(field)
+	if err != nil {
+		return fmt.Errorf("cannot make a record: %w", err)
+	}
 
-	var err error
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := d.recordRepo.Save(ctx, rec); err != nil {
+		return fmt.Errorf("cannot save the record: %w", err)
+	}
+
+	return nil
+}
+
+// handleRecords prints the leaderboard of games with the same rules and goal
+// as the current one, optionally only for given pegs and disks:
+// 'r [PEGS] [DISKS]'.
+func handleRecords(d *CliDependencies, input []string, field *domain.Game) {
+	if d.recordRepo == nil {
+		fmt.Fprintln(d.out, "Records are not available")
+		return
+	}
 
-	// var playersRepo domain.PlayerRepository = inmemory.NewPlayerInmemoryRepo(logger)
-	db, err := sql.Open(postgresql.DriverName, postgresql.DSN)
+	filter := domain.RecordFilter{Ruleset: field.Rules.Name(), Goal: field.Goal.String()}
+	if len(input) > 1 {
+		pegs, err := strconv.Atoi(input[1])
+		if err != nil {
+			fmt.Fprintf(d.out, "Seems like PEGS is not a number: %v\n", err)
+			return
+		}
+		filter.Pegs = pegs
+	}
+	if len(input) > 2 {
+		disks, err := strconv.Atoi(input[2])
+		if err != nil {
+			fmt.Fprintf(d.out, "Seems like DISKS is not a number: %v\n", err)
+			return
+		}
+		filter.Disks = disks
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	records, err := d.recordRepo.GetTop(ctx, filter)
 	if err != nil {
-		logger.Error("cannot open db driver", slog.Any("err", err))
-		os.Exit(1)
+		fmt.Fprintf(d.out, "cannot get records: %v\n", err)
+		return
+	}
+
+	if len(records) == 0 {
+		fmt.Fprintln(d.out, "No records yet")
+		return
 	}
-	defer db.Close()
 
-	err = postgresql.RunMigrations(logger, db)
+	PrintRecords(d.out, records)
+}
+
+func PrintRecords(w io.Writer, records []*domain.Record) {
+	fmt.Fprintln(w, "#\tNick\tSteps\tHints\tPegs\tDisks\tGoal\tRules\tDate")
+	for i, r := range records {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%d\t%d\t%s\t%s\t%s\n",
+			i+1, r.Nickname, r.Steps, r.HintsUsed, r.Pegs, r.Disks, r.Goal, r.Ruleset, r.AchievedAt.Format(time.DateOnly))
+	}
+}
+
+// handleFullScreen plays the game in full-screen mode until the player
+// quits back to commands. Needs a real terminal.
+func handleFullScreen(d *CliDependencies, field *domain.Game) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		fmt.Fprintln(d.out, "Full-screen mode needs a terminal")
+		return
+	}
+
+	renderer := *d.renderer
+	if w, _, err := term.GetSize(fd); err == nil {
+		renderer.Width = w
+	}
+
+	state, err := term.MakeRaw(fd)
 	if err != nil {
-		logger.Error("failed to apply migrations", slog.Any("err", err))
-		os.Exit(1)
+		fmt.Fprintf(d.out, "cannot switch to full-screen mode: %v\n", err)
+		return
 	}
+	defer term.Restore(fd, state)
 
-	var playersRepo domain.PlayerRepository
-	playersRepo, err = postgresql.NewPlayerPostgresRepo(logger, db)
+	tui := cli.NewTUI(d.input, d.out, &renderer, field)
+	tui.OnWin = func(g *domain.Game) {
+		if err := saveRecord(d, g); err != nil {
+			d.logger.Error("cannot save record", slog.Any("err", err))
+		}
+	}
+	if err := tui.Run(); err != nil {
+		d.logger.Error("full-screen mode failed", slog.Any("err", err))
+	}
+}
+
+// handleSave writes the game recording to a file, 'replay FILE' plays it
+// back.
+func handleSave(out io.Writer, input []string, field *domain.Game) {
+	if len(input) < 2 {
+		fmt.Fprintln(out, "Save command requires a file name")
+		return
+	}
+
+	data, err := json.MarshalIndent(field.Recording(), "", "  ")
 	if err != nil {
-		log.Fatal("cannot create player repo: ", err)
+		fmt.Fprintf(out, "cannot encode the game: %v\n", err)
+		return
 	}
 
-	deps := CliDependencies{
-		logger:     logger,
-		out:        out,
-		scanner:    scanner,
-		playerRepo: playersRepo,
+	if err := os.WriteFile(input[1], data, 0o644); err != nil {
+		fmt.Fprintf(out, "cannot save the game: %v\n", err)
+		return
 	}
 
-	play(&deps)
+	fmt.Fprintf(out, "Game is saved to %s\n", input[1])
+}
+
+func handleUndo(out io.Writer, field *domain.Game) {
+	if _, err := field.Undo(); err != nil {
+		fmt.Fprintf(out, "cannot undo: %v\n", err)
+	}
+}
+
+func handleRedo(d *CliDependencies, field *domain.Game) {
+	wasWon := field.IsWon()
+	if _, err := field.Redo(); err != nil {
+		fmt.Fprintf(d.out, "cannot redo: %v\n", err)
+		return
+	}
+
+	if !wasWon && field.IsWon() {
+		handleWin(d, field)
+	}
+}
+
+func handleHint(out io.Writer, field *domain.Game) {
+	m, left, err := field.Hint()
+	if err != nil {
+		fmt.Fprintf(out, "cannot give a hint: %v\n", err)
+		return
+	}
+
+	fmt.Fprintf(out, "Try 'm %d %d', %d moves left to win\n", m.From, m.To, left)
+}
+
+// terminalWidth is the width of the terminal on stdout, COLUMNS overrides
+// it. Output which is not a terminal is 80 columns wide.
+func terminalWidth() int {
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+		return w
+	}
+	return 80
+}
+
+type CliDependencies struct {
+	logger     *slog.Logger
+	out        io.Writer
+	input      *cli.Input // shared by the scanner and the full-screen mode
+	scanner    *bufio.Scanner
+	playerRepo domain.PlayerRepository
+	recordRepo domain.RecordRepository
+	renderer   *cli.Renderer
+
+	// board of new games
+	pegs     uint
+	disks    uint
+	gameOpts []domain.GameOption
+	// first is the game to start with, it is dealt before login
+	first *domain.Game
+}
+
+func main() {
+	cmd, args := "play", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+
+	var err error
+	switch cmd {
+	case "play":
+		err = runPlay(args)
+	case "solve":
+		err = runSolve(args)
+	case "players":
+		err = runPlayers(args)
+	case "records":
+		err = runRecords(args)
+	case "replay":
+		err = runReplay(args)
+	case "migrate":
+		err = runMigrate(args)
+	case "help":
+		fmt.Fprintln(os.Stdout, cli.Usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s\n", cmd, cli.Usage)
+		os.Exit(2)
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
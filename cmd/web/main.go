@@ -1 +1,73 @@
 package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/config"
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/infrastructure/persistance/storage"
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/interface/web"
+)
+
+func main() {
+	loader := config.NewLoader(os.Getenv, config.SectionDatabase, config.SectionServer)
+	loader.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := loader.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	level, _ := cfg.Level()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: level,
+	}))
+
+	st, err := storage.Open(logger, cfg)
+	if err != nil {
+		logger.Error("cannot open storage", slog.Any("err", err))
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	server := web.NewServer(logger, st.Players, st.Records, st.Games)
+
+	srv := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           server,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	gameTTL := cfg.Server.GameTTL
+	go server.ExpireGames(ctx, gameTTL, min(gameTTL, time.Hour))
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logger.Error("failed to shutdown server", slog.Any("err", err))
+		}
+	}()
+
+	logger.Info("server is listening", slog.String("addr", cfg.Server.Addr))
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("server failed", slog.Any("err", err))
+		os.Exit(1)
+	}
+}
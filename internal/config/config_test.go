@@ -0,0 +1,133 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(k string) string { return vars[k] }
+}
+
+func load(t *testing.T, getenv func(string) string, args []string, sections ...Section) (*Config, error) {
+	t.Helper()
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	l := NewLoader(getenv, sections...)
+	l.RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return l.Load()
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestDefaults(t *testing.T) {
+	cfg, err := load(t, env(nil), nil, SectionDatabase, SectionServer)
+	require.NoError(t, err)
+	assert.Equal(t, Default(), *cfg)
+}
+
+func TestPrecedence(t *testing.T) {
+	path := writeFile(t, `
+log_level: debug
+database:
+  dsn: postgres://file
+  max_open_conns: 10
+  max_idle_conns: 5
+server:
+  addr: ":7000"
+  game_ttl: 2h
+`)
+	vars := map[string]string{
+		"HANOI_CONFIG":            path,
+		"HANOI_DB_DSN":            "postgres://env",
+		"HANOI_DB_MAX_OPEN_CONNS": "20",
+		"HANOI_ADDR":              ":7001",
+	}
+
+	cfg, err := load(t, env(vars), []string{"-addr", ":7002"}, SectionDatabase, SectionServer)
+	require.NoError(t, err)
+
+	assert.Equal(t, "debug", cfg.LogLevel, "from file")
+	assert.Equal(t, 5, cfg.Database.MaxIdleConns, "from file")
+	assert.Equal(t, 2*time.Hour, cfg.Server.GameTTL, "from file")
+	assert.Equal(t, "postgres://env", cfg.Database.DSN, "env overrides file")
+	assert.Equal(t, 20, cfg.Database.MaxOpenConns, "env overrides file")
+	assert.Equal(t, ":7002", cfg.Server.Addr, "flag overrides env")
+	assert.Equal(t, Default().Database.Migrations, cfg.Database.Migrations, "default")
+}
+
+func TestConfigFlag(t *testing.T) {
+	path := writeFile(t, "storage: memory\n")
+
+	cfg, err := load(t, env(nil), []string{"-config", path}, SectionDatabase)
+	require.NoError(t, err)
+	assert.Equal(t, StorageMemory, cfg.Storage)
+}
+
+func TestSections(t *testing.T) {
+	_, err := load(t, env(nil), []string{"-addr", ":1"}, SectionDatabase)
+	assert.Error(t, err, "server flags are not registered")
+
+	cfg, err := load(t, env(map[string]string{"HANOI_ADDR": ":1"}), nil, SectionDatabase)
+	require.NoError(t, err)
+	assert.Equal(t, Default().Server.Addr, cfg.Server.Addr, "server env is ignored")
+}
+
+func TestInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		vars map[string]string
+		args []string
+		file string
+	}{
+		{name: "bad flag value", args: []string{"-db-max-open-conns", "many"}},
+		{name: "bad env value", vars: map[string]string{"HANOI_GAME_TTL": "day"}},
+		{name: "unknown file field", file: "databse:\n  dsn: x\n"},
+		{name: "missing file", vars: map[string]string{"HANOI_CONFIG": "/does/not/exist.yaml"}},
+		{name: "log level", args: []string{"-log-level", "loud"}},
+		{name: "storage", args: []string{"-storage", "floppy"}},
+		{name: "empty dsn", args: []string{"-dsn", ""}},
+		{name: "idle over open", args: []string{"-db-max-open-conns", "2", "-db-max-idle-conns", "3"}},
+		{name: "negative timeout", args: []string{"-read-timeout", "-1s"}},
+		{name: "zero game ttl", args: []string{"-game-ttl", "0s"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := tt.vars
+			if tt.file != "" {
+				vars = map[string]string{"HANOI_CONFIG": writeFile(t, tt.file)}
+			}
+			_, err := load(t, env(vars), tt.args, SectionDatabase, SectionServer)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestValidateReportsAll(t *testing.T) {
+	c := Default()
+	c.LogLevel = "loud"
+	c.Server.Addr = ""
+
+	err := c.Validate()
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	assert.ErrorContains(t, err, "log level")
+	assert.ErrorContains(t, err, "server address")
+}
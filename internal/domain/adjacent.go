@@ -0,0 +1,66 @@
+package domain
+
+import "fmt"
+
+const AdjacentRulesetName = "adjacent"
+
+// AdjacentRuleset puts pegs in a row and lets disks go only to a neighbouring
+// peg, so a disk from peg 0 reaches peg 2 through peg 1. Otherwise the rules
+// are standard.
+type AdjacentRuleset struct{}
+
+func (AdjacentRuleset) Name() string {
+	return AdjacentRulesetName
+}
+
+func (r AdjacentRuleset) CheckMove(g *Game, from int, to int) error {
+	if !r.canMove(from, to, len(g.Pegs)) {
+		return fmt.Errorf("%w: disks go only to a neighbouring peg", ErrIllegalMove)
+	}
+	return StandardRuleset{}.CheckMove(g, from, to)
+}
+
+func (AdjacentRuleset) IsWon(g *Game) bool {
+	return g.goalReached()
+}
+
+// Solve walks the biggest misplaced disk to its peg, smaller disks wait
+// behind it. For three pegs it takes 3^n-1 moves to carry a tower from one
+// end to the other, which is optimal. Small boards with more pegs are solved
+// by SolveExact, bigger ones are not searched at all.
+func (AdjacentRuleset) Solve(g *Game) ([]Move, error) {
+	if len(g.Pegs) < 3 {
+		return nil, ErrUnsupportedPegs
+	}
+
+	if searchesExactly(g) {
+		return SolveExact(g, hintMaxStates)
+	}
+
+	pegs := len(g.Pegs)
+	return solveWalking(g, func(pos []int) *walkSolver {
+		return &walkSolver{
+			pos: pos,
+			step: func(p int, t int) int {
+				if t > p {
+					return p + 1
+				}
+				return p - 1
+			},
+			spare: func(p int, q int) int {
+				if behind := 2*p - q; behind >= 0 && behind < pegs {
+					return behind
+				}
+				return 2*q - p
+			},
+		}
+	})
+}
+
+func (AdjacentRuleset) solvesShortest(g *Game) bool {
+	return searchesExactly(g)
+}
+
+func (AdjacentRuleset) canMove(from int, to int, pegs int) bool {
+	return from-to == 1 || to-from == 1
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdjacentMoves(t *testing.T) {
+	g, err := NewGame(3, 3, &Player{}, DefaultColorPicker(), WithStartLayout(ClassicStart), WithRuleset(AdjacentRuleset{}))
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, g.MoveDisk(0, 2), ErrIllegalMove)
+	require.NoError(t, g.MoveDisk(0, 1))
+	require.NoError(t, g.MoveDisk(1, 2))
+	assert.ErrorIs(t, g.MoveDisk(2, 0), ErrIllegalMove)
+	assert.ErrorIs(t, g.MoveDisk(1, 0), ErrEmptyPeg)
+	require.NoError(t, g.MoveDisk(0, 1))
+	require.NoError(t, g.MoveDisk(2, 1))
+	assert.ErrorIs(t, g.MoveDisk(0, 1), ErrBiggerOnSmaller)
+}
+
+func TestSolveAdjacentTower(t *testing.T) {
+	pow := 1
+	for disks := 1; disks <= 7; disks++ {
+		pow *= 3
+		for target := 1; target <= 2; target++ {
+			t.Run(fmt.Sprintf("%d disks to peg %d", disks, target), func(t *testing.T) {
+				g, err := NewGame(3, uint(disks), &Player{}, DefaultColorPicker(),
+					WithStartLayout(ClassicStart), WithGoal(PegGoal(target)), WithRuleset(AdjacentRuleset{}))
+				require.NoError(t, err)
+
+				moves, err := Solve(g)
+				require.NoError(t, err)
+				if target == 2 {
+					assert.Len(t, moves, pow-1)
+				}
+
+				exact, err := SolveExact(g, 0)
+				require.NoError(t, err)
+				assert.Len(t, exact, len(moves), "solution is the shortest")
+
+				applyMoves(t, g, moves)
+				assert.True(t, g.IsWon())
+			})
+		}
+	}
+}
+
+func TestSolveAdjacentBigBoard(t *testing.T) {
+	g, err := NewGame(5, 10, &Player{}, DefaultColorPicker(),
+		WithStartLayout(ClassicStart), WithGoal(PegGoal(4)), WithRuleset(AdjacentRuleset{}))
+	require.NoError(t, err)
+
+	moves, err := Solve(g)
+	require.NoError(t, err)
+	applyMoves(t, g, moves)
+	assert.True(t, g.IsWon())
+}
@@ -0,0 +1,267 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"slices"
+)
+
+var ErrUnsupportedBoard = errors.New("board does not suit the rules")
+
+const BicolorRulesetName = "bicolor"
+
+// towerColors tell towers of BicolorRuleset apart, neighbours contrast.
+var towerColors = []color.Color{Red, Blue, Yellow, Green, Purple, Orange, Cyan}
+
+// BicolorRuleset has several towers of the same sizes told apart by color.
+// Disks of equal size may lie on each other and the game is won when every
+// color is stacked on its own peg.
+//
+// Disk IDs go by size and then by color, so with two colors disks 1 and 2
+// are the smallest ones of color 0 and 1. Pegs of colors are set by a layout
+// goal, by default they are the last pegs and one more peg is left spare.
+type BicolorRuleset struct {
+	// Colors is the number of towers, zero means two
+	Colors int
+}
+
+func (r BicolorRuleset) Name() string {
+	if c := r.colors(); c != 2 {
+		return fmt.Sprintf("%s:%d", BicolorRulesetName, c)
+	}
+	return BicolorRulesetName
+}
+
+func (BicolorRuleset) CheckMove(g *Game, from int, to int) error {
+	return StandardRuleset{}.CheckMove(g, from, to)
+}
+
+func (BicolorRuleset) IsWon(g *Game) bool {
+	return g.goalReached()
+}
+
+// Solve sorts disks from the biggest size. Every size is gathered with all
+// smaller disks on the spare peg, then disks of that size go to their pegs
+// one by one while smaller ones wait on top of the sorted towers. It is not
+// the shortest solution.
+func (r BicolorRuleset) Solve(g *Game) ([]Move, error) {
+	if g.Goal.Kind != GoalLayout {
+		return nil, fmt.Errorf("%w: %s game needs a layout goal", ErrInvalidGoal, r.Name())
+	}
+	targets, err := r.colorPegs(g.Goal.Layout)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.IsWon() {
+		return []Move{}, nil
+	}
+
+	s := &bicolorSolver{
+		stacks:  make([][]uint, len(g.Pegs)),
+		colors:  r.colors(),
+		targets: targets,
+		moves:   []Move{},
+	}
+	for i, p := range g.Pegs {
+		for d := p.TopDisk; d != nil; d = d.Next {
+			s.stacks[i] = append(s.stacks[i], d.ID)
+		}
+		slices.Reverse(s.stacks[i])
+	}
+	for i := range g.Pegs {
+		if !slices.Contains(targets, i) {
+			s.spare = i
+			break
+		}
+	}
+
+	for size := uint(g.TotalDisks / s.colors); size >= 1 && !s.tooLong; size-- {
+		s.sort(size)
+	}
+
+	if s.tooLong {
+		return nil, ErrSolutionTooLong
+	}
+	return s.moves, nil
+}
+
+func (r BicolorRuleset) colors() int {
+	if r.Colors < 2 {
+		return 2
+	}
+	return r.Colors
+}
+
+func (r BicolorRuleset) disk(id uint, total uint) (uint, color.Color) {
+	c := uint(r.colors())
+	return (id-1)/c + 1, towerColors[(id-1)%c%uint(len(towerColors))]
+}
+
+// checkBoard needs towers of distinct colors, disks to split evenly into
+// them and a spare peg. The goal is to sort disks by color, so only a layout
+// goal which does that is allowed and any peg goal means the last pegs.
+func (r BicolorRuleset) checkBoard(pegs int, disks int, goal Goal) (Goal, error) {
+	c := r.colors()
+	if c > len(towerColors) {
+		return Goal{}, fmt.Errorf("%w: at most %d colors can be told apart", ErrUnsupportedBoard, len(towerColors))
+	}
+	if disks%c != 0 {
+		return Goal{}, fmt.Errorf("%w: %d disks cannot be split into %d colors", ErrUnsupportedBoard, disks, c)
+	}
+	if pegs < c+1 {
+		return Goal{}, fmt.Errorf("%w: %d colors need at least %d pegs", ErrUnsupportedBoard, c, c+1)
+	}
+
+	switch goal.Kind {
+	case GoalAnyPeg:
+		l := make(Layout, pegs)
+		for id := uint(disks); id >= 1; id-- {
+			p := pegs - c + int(id-1)%c
+			l[p] = append(l[p], id)
+		}
+		return LayoutGoal(l), nil
+	case GoalLayout:
+		if _, err := r.colorPegs(goal.Layout); err != nil {
+			return Goal{}, err
+		}
+		return goal, nil
+	default:
+		return Goal{}, fmt.Errorf("%w: disks of %s game are sorted by color", ErrInvalidGoal, r.Name())
+	}
+}
+
+// colorPegs checks every color is stacked on its own peg in the layout and
+// there are more pegs than colors. Returns peg of every color.
+func (r BicolorRuleset) colorPegs(l Layout) ([]int, error) {
+	c := r.colors()
+	targets := make([]int, c)
+	for i := range targets {
+		targets[i] = -1
+	}
+
+	for p, ids := range l {
+		for _, id := range ids {
+			col := int(id-1) % c
+			if int(ids[0]-1)%c != col {
+				return nil, fmt.Errorf("%w: colors are mixed on peg %d", ErrInvalidGoal, p)
+			}
+			if targets[col] != -1 && targets[col] != p {
+				return nil, fmt.Errorf("%w: color %d is on pegs %d and %d", ErrInvalidGoal, col, targets[col], p)
+			}
+			targets[col] = p
+		}
+	}
+
+	if slices.Contains(targets, -1) {
+		return nil, fmt.Errorf("%w: every color should have a peg", ErrInvalidGoal)
+	}
+	if len(l) < c+1 {
+		return nil, fmt.Errorf("%w: %d colors need at least %d pegs", ErrUnsupportedBoard, c, c+1)
+	}
+	return targets, nil
+}
+
+type bicolorSolver struct {
+	// stacks hold disk IDs of every peg from bottom to top
+	stacks  [][]uint
+	colors  int
+	targets []int
+	spare   int
+	moves   []Move
+	tooLong bool
+}
+
+func (s *bicolorSolver) size(id uint) uint {
+	return (id-1)/uint(s.colors) + 1
+}
+
+func (s *bicolorSolver) color(id uint) int {
+	return int(id-1) % s.colors
+}
+
+// top returns the top disk of peg p, zero when it is empty.
+func (s *bicolorSolver) top(p int) uint {
+	if len(s.stacks[p]) == 0 {
+		return 0
+	}
+	return s.stacks[p][len(s.stacks[p])-1]
+}
+
+// sort puts disks of given size to their pegs. Bigger disks must be sorted
+// already and never move.
+func (s *bicolorSolver) sort(size uint) {
+	s.gather(size, s.spare)
+	if s.tooLong {
+		return
+	}
+
+	// The bottom disk on the spare peg goes last, smaller disks wait on its
+	// peg meanwhile and then on the peg of the next color.
+	last := s.color(s.stacks[s.spare][0])
+	s.gather(size-1, s.targets[last])
+	for len(s.stacks[s.spare]) > 1 && !s.tooLong {
+		s.move(s.spare, s.targets[s.color(s.top(s.spare))])
+	}
+	s.gather(size-1, s.targets[(last+1)%s.colors])
+	s.move(s.spare, s.targets[last])
+}
+
+// gather stacks all disks up to given size on peg t, bigger disks do not
+// move. Disks of the same size are taken peg by peg, smaller ones are put
+// aside on some other peg every time.
+func (s *bicolorSolver) gather(size uint, t int) {
+	if size == 0 || s.tooLong {
+		return
+	}
+
+	for !s.tooLong {
+		from := s.pegWith(size, t)
+		if from == -1 {
+			break
+		}
+
+		aside := 0
+		for aside == from || aside == t {
+			aside++
+		}
+		s.gather(size-1, aside)
+		for id := s.top(from); id != 0 && s.size(id) == size && !s.tooLong; id = s.top(from) {
+			s.move(from, t)
+		}
+	}
+
+	s.gather(size-1, t)
+}
+
+// pegWith returns a peg other than except holding a disk of given size, -1
+// when there is none.
+func (s *bicolorSolver) pegWith(size uint, except int) int {
+	for p, ids := range s.stacks {
+		if p == except {
+			continue
+		}
+		for _, id := range ids {
+			if s.size(id) == size {
+				return p
+			}
+		}
+	}
+	return -1
+}
+
+func (s *bicolorSolver) move(from int, to int) {
+	if s.tooLong {
+		return
+	}
+	if len(s.moves) >= maxSolutionMoves {
+		s.tooLong = true
+		return
+	}
+
+	id := s.top(from)
+	s.stacks[from] = s.stacks[from][:len(s.stacks[from])-1]
+	s.stacks[to] = append(s.stacks[to], id)
+	s.moves = append(s.moves, Move{From: from, To: to})
+}
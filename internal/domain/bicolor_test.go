@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBicolorBoard(t *testing.T) {
+	g, err := NewGame(3, 6, &Player{}, DefaultColorPicker(), WithStartLayout(ClassicStart), WithRuleset(BicolorRuleset{}))
+	require.NoError(t, err)
+
+	assert.Equal(t, LayoutGoal(Layout{{}, {5, 3, 1}, {6, 4, 2}}), g.Goal)
+	var sizes []uint
+	for d := g.Pegs[0].TopDisk; d != nil; d = d.Next {
+		sizes = append(sizes, d.Size)
+	}
+	assert.Equal(t, []uint{1, 1, 2, 2, 3, 3}, sizes)
+	assert.Equal(t, g.Pegs[0].TopDisk.Next.Next.Color, g.Pegs[0].TopDisk.Color, "disks of one color")
+	assert.NotEqual(t, g.Pegs[0].TopDisk.Next.Color, g.Pegs[0].TopDisk.Color, "disks of other colors")
+
+	require.NoError(t, g.MoveDisk(0, 1))
+	require.NoError(t, g.MoveDisk(0, 1), "equal disks lie on each other")
+	assert.ErrorIs(t, g.MoveDisk(0, 1), ErrBiggerOnSmaller)
+	assert.Equal(t, Layout{{6, 5, 4, 3}, {1, 2}, {}}, g.Layout())
+}
+
+func TestBicolorBoardErrors(t *testing.T) {
+	tests := []struct {
+		pegs  uint
+		disks uint
+		rules BicolorRuleset
+		opts  []GameOption
+		want  error
+	}{
+		{3, 5, BicolorRuleset{}, nil, ErrUnsupportedBoard},
+		{3, 6, BicolorRuleset{Colors: 3}, nil, ErrUnsupportedBoard},
+		{9, 8, BicolorRuleset{Colors: 8}, nil, ErrUnsupportedBoard},
+		{3, 6, BicolorRuleset{}, []GameOption{WithGoal(PegGoal(2))}, ErrInvalidGoal},
+		{3, 4, BicolorRuleset{}, []GameOption{WithGoal(LayoutGoal(Layout{{4, 3}, {2, 1}, {}}))}, ErrInvalidGoal},
+		{3, 4, BicolorRuleset{}, []GameOption{WithGoal(LayoutGoal(Layout{{3, 1}, {}, {4}, {2}}))}, ErrInvalidGoal},
+		{3, 4, BicolorRuleset{}, []GameOption{WithStartLayout(DistanceStart(3))}, ErrUnsupportedRuleset},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d pegs %d disks %s", tt.pegs, tt.disks, tt.rules.Name()), func(t *testing.T) {
+			opts := append([]GameOption{WithRuleset(tt.rules)}, tt.opts...)
+			_, err := NewGame(tt.pegs, tt.disks, &Player{}, DefaultColorPicker(), opts...)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestBicolorLayout(t *testing.T) {
+	// disks 3 and 4 are of the same size
+	l := Layout{{3, 4, 1}, {2}, {}}
+	_, err := NewGame(3, 4, &Player{}, DefaultColorPicker(), WithStartLayout(CustomStart(l)))
+	assert.ErrorIs(t, err, ErrInvalidLayout, "sizes are unique in standard game")
+
+	g, err := NewGame(3, 4, &Player{}, DefaultColorPicker(), WithStartLayout(CustomStart(l)), WithRuleset(BicolorRuleset{}))
+	require.NoError(t, err)
+	assert.Equal(t, l, g.Layout())
+
+	_, err = NewGame(3, 4, &Player{}, DefaultColorPicker(), WithStartLayout(CustomStart(Layout{{1, 3}, {2, 4}, {}})), WithRuleset(BicolorRuleset{}))
+	assert.ErrorIs(t, err, ErrInvalidLayout)
+}
+
+func TestSolveBicolor(t *testing.T) {
+	boards := []struct {
+		pegs  uint
+		disks uint
+		rules BicolorRuleset
+		goal  Goal
+	}{
+		{3, 2, BicolorRuleset{}, AnyPegGoal()},
+		{3, 8, BicolorRuleset{}, AnyPegGoal()},
+		{3, 8, BicolorRuleset{}, LayoutGoal(Layout{{7, 5, 3, 1}, {}, {8, 6, 4, 2}})},
+		{4, 8, BicolorRuleset{}, AnyPegGoal()},
+		{4, 9, BicolorRuleset{Colors: 3}, AnyPegGoal()},
+	}
+	for _, b := range boards {
+		for seed := range int64(10) {
+			t.Run(fmt.Sprintf("%d pegs %d disks %s seed %d", b.pegs, b.disks, b.rules.Name(), seed), func(t *testing.T) {
+				g, err := NewGame(b.pegs, b.disks, &Player{}, DefaultColorPicker(),
+					WithSeed(seed), WithGoal(b.goal), WithRuleset(b.rules))
+				require.NoError(t, err)
+
+				moves, err := Solve(g)
+				require.NoError(t, err)
+				applyMoves(t, g, moves)
+				assert.True(t, g.IsWon())
+
+				moves, err = Solve(g)
+				require.NoError(t, err)
+				assert.Empty(t, moves)
+			})
+		}
+	}
+}
+
+func TestBicolorReplay(t *testing.T) {
+	rules, err := ParseRuleset("bicolor:3")
+	require.NoError(t, err)
+	g, err := NewGame(4, 6, &Player{}, DefaultColorPicker(), WithSeed(7), WithRuleset(rules))
+	require.NoError(t, err)
+
+	_, err = SolveExact(g, 0)
+	assert.ErrorIs(t, err, ErrUnsupportedRuleset)
+	moves, err := Solve(g)
+	require.NoError(t, err)
+	par, err := Par(g)
+	require.NoError(t, err)
+	assert.Equal(t, len(moves), par)
+
+	applyMoves(t, g, moves)
+	record, err := NewRecord(g)
+	require.NoError(t, err)
+	assert.Equal(t, AnyPegGoal().String(), record.Goal, "colors sorted onto the last pegs rank under any peg")
+	rec := g.Recording()
+	assert.Equal(t, "bicolor:3", rec.Ruleset)
+
+	replayed, err := Replay(rec, &Player{}, DefaultColorPicker())
+	require.NoError(t, err)
+	assert.Equal(t, g.Layout(), replayed.Layout())
+	assert.Equal(t, g.Goal, replayed.Goal)
+	assert.Equal(t, rules, replayed.Rules)
+	assert.True(t, replayed.IsWon())
+
+	_, err = ParseRuleset("bicolor:1")
+	assert.ErrorIs(t, err, ErrUnknownRuleset)
+	_, err = ParseRuleset("bicolor:8")
+	assert.ErrorIs(t, err, ErrUnknownRuleset, "more towers than colors")
+	_, err = ParseRuleset("bicolor:7")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,61 @@
+package domain
+
+import "fmt"
+
+const CyclicRulesetName = "cyclic"
+
+// CyclicRuleset only lets disks go clockwise: from peg i to peg (i+1) mod k.
+// Otherwise the rules are standard.
+type CyclicRuleset struct{}
+
+func (CyclicRuleset) Name() string {
+	return CyclicRulesetName
+}
+
+func (r CyclicRuleset) CheckMove(g *Game, from int, to int) error {
+	if !r.canMove(from, to, len(g.Pegs)) {
+		return fmt.Errorf("%w: disks go only clockwise, from peg %d to peg %d",
+			ErrIllegalMove, from, (from+1)%len(g.Pegs))
+	}
+	return StandardRuleset{}.CheckMove(g, from, to)
+}
+
+func (CyclicRuleset) IsWon(g *Game) bool {
+	return g.goalReached()
+}
+
+// Solve walks the biggest misplaced disk round to its peg, every time
+// parking smaller disks on the peg after the next one. It is the optimal
+// solution for a tower on three pegs. Small boards with more pegs are solved
+// by SolveExact, on bigger ones the walk is far from the shortest, so their
+// par is not told.
+func (CyclicRuleset) Solve(g *Game) ([]Move, error) {
+	if len(g.Pegs) < 3 {
+		return nil, ErrUnsupportedPegs
+	}
+
+	if searchesExactly(g) {
+		return SolveExact(g, hintMaxStates)
+	}
+
+	pegs := len(g.Pegs)
+	return solveWalking(g, func(pos []int) *walkSolver {
+		return &walkSolver{
+			pos:   pos,
+			step:  func(p int, t int) int { return (p + 1) % pegs },
+			spare: func(p int, q int) int { return (q + 1) % pegs },
+		}
+	})
+}
+
+func (CyclicRuleset) solvesShortest(g *Game) bool {
+	return searchesExactly(g)
+}
+
+func (CyclicRuleset) knowsPar(g *Game) bool {
+	return len(g.Pegs) == 3 || boardFits(g, hintMaxStates)
+}
+
+func (CyclicRuleset) canMove(from int, to int, pegs int) bool {
+	return to == (from+1)%pegs
+}
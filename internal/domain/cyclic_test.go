@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cyclicTower returns how many moves take a tower of n disks one peg and two
+// pegs clockwise on three pegs.
+func cyclicTower(n int) (one int, two int) {
+	for range n {
+		one, two = 2*two+1, 2*two+one+2
+	}
+	return one, two
+}
+
+func TestCyclicMoves(t *testing.T) {
+	g, err := NewGame(3, 3, &Player{}, DefaultColorPicker(), WithStartLayout(ClassicStart), WithRuleset(CyclicRuleset{}))
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, g.MoveDisk(0, 2), ErrIllegalMove)
+	assert.ErrorIs(t, g.MoveDisk(0, 0), ErrIllegalMove, "disk stays on its peg")
+	assert.Zero(t, g.Step)
+	assert.Zero(t, g.NetSteps())
+	require.NoError(t, g.MoveDisk(0, 1))
+	assert.ErrorIs(t, g.MoveDisk(1, 0), ErrIllegalMove)
+	assert.ErrorIs(t, g.MoveDisk(0, 1), ErrBiggerOnSmaller)
+	require.NoError(t, g.MoveDisk(1, 2))
+	require.NoError(t, g.MoveDisk(2, 0))
+	assert.Equal(t, uint(3), g.Step)
+}
+
+func TestSolveCyclicTower(t *testing.T) {
+	for disks := 1; disks <= 7; disks++ {
+		for target := 1; target <= 2; target++ {
+			t.Run(fmt.Sprintf("%d disks to peg %d", disks, target), func(t *testing.T) {
+				g, err := NewGame(3, uint(disks), &Player{}, DefaultColorPicker(),
+					WithStartLayout(ClassicStart), WithGoal(PegGoal(target)), WithRuleset(CyclicRuleset{}))
+				require.NoError(t, err)
+
+				moves, err := Solve(g)
+				require.NoError(t, err)
+
+				one, two := cyclicTower(disks)
+				assert.Equal(t, []int{one, two}[target-1], len(moves))
+
+				exact, err := SolveExact(g, 0)
+				require.NoError(t, err)
+				assert.Len(t, exact, len(moves), "solution is the shortest")
+
+				applyMoves(t, g, moves)
+				assert.True(t, g.IsWon())
+			})
+		}
+	}
+}
+
+func TestSolveCyclicMorePegs(t *testing.T) {
+	for pegs := uint(4); pegs <= 5; pegs++ {
+		g, err := NewGame(pegs, 5, &Player{}, DefaultColorPicker(),
+			WithStartLayout(ClassicStart), WithGoal(PegGoal(1)), WithRuleset(CyclicRuleset{}))
+		require.NoError(t, err)
+
+		exact, err := SolveExact(g, 0)
+		require.NoError(t, err)
+		moves, err := Solve(g)
+		require.NoError(t, err)
+		assert.Len(t, moves, len(exact), "small boards are searched")
+	}
+
+	g, err := NewGame(4, 11, &Player{}, DefaultColorPicker(),
+		WithStartLayout(ClassicStart), WithGoal(PegGoal(1)), WithRuleset(CyclicRuleset{}))
+	require.NoError(t, err)
+	moves, err := Solve(g)
+	require.NoError(t, err)
+	applyMoves(t, g, moves)
+	assert.True(t, g.IsWon())
+
+	_, err = Par(g)
+	assert.ErrorIs(t, err, ErrUnknownPar, "the walk is far from the shortest")
+}
+
+func TestCyclicDistanceStart(t *testing.T) {
+	for n := range 12 {
+		g, err := NewGame(3, 4, &Player{}, DefaultColorPicker(), WithSeed(int64(n)),
+			WithStartLayout(DistanceStart(n)), WithGoal(PegGoal(2)), WithRuleset(CyclicRuleset{}))
+		require.NoError(t, err)
+
+		moves, err := SolveExact(g, 0)
+		require.NoError(t, err)
+		assert.Len(t, moves, n)
+	}
+}
+
+func TestPar(t *testing.T) {
+	g, err := NewGame(3, 4, &Player{}, DefaultColorPicker(), WithStartLayout(ClassicStart), WithGoal(PegGoal(1)))
+	require.NoError(t, err)
+	par, err := Par(g)
+	require.NoError(t, err)
+	assert.Equal(t, 15, par)
+
+	g, err = NewGame(3, 4, &Player{}, DefaultColorPicker(),
+		WithStartLayout(ClassicStart), WithGoal(PegGoal(1)), WithRuleset(CyclicRuleset{}))
+	require.NoError(t, err)
+	one, _ := cyclicTower(4)
+
+	moves, err := Solve(g)
+	require.NoError(t, err)
+	applyMoves(t, g, moves[:3])
+	par, err = Par(g)
+	require.NoError(t, err)
+	assert.Equal(t, one, par, "par is counted from the start")
+}
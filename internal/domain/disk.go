@@ -14,10 +14,58 @@ var (
 
 var diskColors = []color.Color{Red, Orange, Yellow, Green, Cyan, Blue, Purple}
 
+// RGB returns 8-bit channels of the disk color as they are meant to be shown.
+// Palette above keeps plain RGB in color.RGBA with alpha below the channels,
+// so color.RGBA is read as is instead of being treated as premultiplied.
+func RGB(c color.Color) (r uint8, g uint8, b uint8) {
+	if rgba, ok := c.(color.RGBA); ok {
+		return rgba.R, rgba.G, rgba.B
+	}
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return n.R, n.G, n.B
+}
+
 // Rings are storeg in a peg
 // Next is the disk below current one in the same peg
 type Disk struct {
+	// ID is the number of the disk in layouts. Rulesets where sizes are
+	// unique use the size.
+	ID    uint
 	Size  uint
 	Color color.Color
-	Next  *Disk
+	// Up is the pole facing up, the other one faces down. Disks of most
+	// rulesets have no poles.
+	Up   Pole
+	Next *Disk
+}
+
+// Pole is a face of a magnetic disk. Magnetic disks turn over on every move
+// and like poles of disks lying on each other cannot touch.
+type Pole int
+
+const (
+	NoPole Pole = iota
+	North
+	South
+)
+
+// Flip is the pole facing the other way.
+func (p Pole) Flip() Pole {
+	switch p {
+	case North:
+		return South
+	case South:
+		return North
+	}
+	return NoPole
+}
+
+func (p Pole) String() string {
+	switch p {
+	case North:
+		return "N"
+	case South:
+		return "S"
+	}
+	return ""
 }
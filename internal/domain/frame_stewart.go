@@ -0,0 +1,332 @@
+package domain
+
+import (
+	"errors"
+	"math"
+)
+
+var ErrBoardTooLarge = errors.New("board is too large for the solver")
+
+// maxFrameStewartDisks limits boards with 4+ pegs, planning is polynomial
+// but far from cheap.
+const maxFrameStewartDisks = 24
+
+// FrameStewartMoves returns presumed minimal number of moves needed to move
+// a tower of disks from one peg to another. For 3 pegs it is exactly 2^n-1.
+// Saturates at math.MaxUint64, which is also returned when the tower cannot
+// be moved at all.
+//
+// Moves count grows by 2^t exactly C(t+pegs-3, pegs-3) times in a row,
+// which is much faster than running the recurrence itself.
+func FrameStewartMoves(pegs uint, disks uint) uint64 {
+	if disks == 0 {
+		return 0
+	}
+	if pegs < 3 {
+		if pegs == 2 && disks == 1 {
+			return 1
+		}
+		return math.MaxUint64
+	}
+
+	var total uint64
+	left := uint64(disks)
+	for t := uint64(0); left > 0; t++ {
+		if t >= 64 {
+			return math.MaxUint64
+		}
+
+		n := min(binomial(t+uint64(pegs)-3, uint64(pegs)-3), left)
+		left -= n
+		total = satAdd(total, satMul(n, 1<<t))
+	}
+
+	return total
+}
+
+// binomial computes C(n, k) saturating at math.MaxUint64.
+func binomial(n uint64, k uint64) uint64 {
+	k = min(k, n-k)
+
+	var c uint64 = 1
+	for i := uint64(1); i <= k; i++ {
+		if c > math.MaxUint64/(n-k+i) {
+			return math.MaxUint64
+		}
+		// C(n-k+i, i) is always an integer, so division is exact.
+		c = c * (n - k + i) / i
+	}
+
+	return c
+}
+
+// satAdd adds moves counts, saturating at math.MaxUint64.
+func satAdd(a uint64, b uint64) uint64 {
+	if a > math.MaxUint64-b {
+		return math.MaxUint64
+	}
+	return a + b
+}
+
+func satMul(a uint64, b uint64) uint64 {
+	if a != 0 && b > math.MaxUint64/a {
+		return math.MaxUint64
+	}
+	return a * b
+}
+
+// splitFor returns how many top disks Frame–Stewart puts aside when moving
+// a tower of n disks with k pegs.
+func (s *solver) splitFor(k int, n int) int {
+	key := [2]int{k, n}
+	if l, ok := s.split[key]; ok {
+		return l
+	}
+
+	best, split := uint64(math.MaxUint64), 1
+	for l := 1; l < n; l++ {
+		c := satAdd(satMul(2, FrameStewartMoves(uint(k), uint(l))), FrameStewartMoves(uint(k-1), uint(n-l)))
+		if c < best {
+			best, split = c, l
+		}
+	}
+
+	s.split[key] = split
+	return split
+}
+
+// solveFrameStewart gathers disks on one of target pegs which gives the
+// shortest plan with four or more pegs.
+//
+// Frame–Stewart idea is applied to scattered disks too: to stack disks
+// lo..hi on peg t, top l of them are gathered on some aside peg, the rest is
+// stacked on t without touching that peg, and then the aside tower is moved
+// on top of them. The planner tries every split and every sensible aside peg.
+func solveFrameStewart(pos []int, pegs int, targets []int) ([]Move, error) {
+	if len(pos) > maxFrameStewartDisks {
+		return nil, ErrBoardTooLarge
+	}
+
+	p := newFSPlanner(pos)
+	target := targets[0]
+	for _, t := range targets[1:] {
+		if p.plan(1, len(pos), t, pegs).cost < p.plan(1, len(pos), target, pegs).cost {
+			target = t
+		}
+	}
+
+	best := p.plan(1, len(pos), target, pegs).cost
+	if best > maxSolutionMoves {
+		return nil, ErrSolutionTooLong
+	}
+
+	s := newSolver(pos, pegs)
+	s.moves = make([]Move, 0, best)
+	s.gatherFS(p, 1, len(pos), target, allPegs(pegs))
+
+	return s.moves, nil
+}
+
+// solveFrameStewartToLayout moves disks from one arbitrary position to
+// another with four or more pegs. Disks smaller than the biggest misplaced
+// one are gathered on the peg which is the cheapest to gather at and to
+// scatter from, then the disk goes straight to its place.
+func solveFrameStewartToLayout(pos []int, goal []int, pegs int) ([]Move, error) {
+	n := len(pos)
+	for n > 0 && pos[n-1] == goal[n-1] {
+		n--
+	}
+	if n == 0 {
+		return []Move{}, nil
+	}
+	if n > maxFrameStewartDisks {
+		return nil, ErrBoardTooLarge
+	}
+
+	from, to := pos[n-1], goal[n-1]
+	ps, pg := newFSPlanner(pos[:n-1]), newFSPlanner(goal[:n-1])
+
+	spare := -1
+	var best uint64 = math.MaxUint64
+	for c := range pegs {
+		if c == from || c == to {
+			continue
+		}
+		cost := satAdd(satAdd(ps.plan(1, n-1, c, pegs).cost, 1), pg.plan(1, n-1, c, pegs).cost)
+		if cost < best {
+			best, spare = cost, c
+		}
+	}
+
+	if best > maxSolutionMoves {
+		return nil, ErrSolutionTooLong
+	}
+
+	s := newSolver(pos, pegs)
+	s.moves = make([]Move, 0, best)
+	s.gatherFS(ps, 1, n-1, spare, allPegs(pegs))
+	s.move(n, to)
+
+	r := newSolver(goal, pegs)
+	r.gatherFS(pg, 1, n-1, spare, allPegs(pegs))
+	s.unwind(r.moves)
+
+	return s.moves, nil
+}
+
+func allPegs(pegs int) []int {
+	all := make([]int, pegs)
+	for i := range all {
+		all[i] = i
+	}
+	return all
+}
+
+func isGathered(pos []int) bool {
+	for _, p := range pos {
+		if p != pos[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// fsKey describes a subproblem: stack disks lo..hi on peg t having k pegs.
+// Disks of the range are always at their initial positions when subproblem
+// starts, smaller ones are out of reach. Target peg which holds none of the
+// range disks is no different from any other empty peg, so it is stored as -1.
+type fsKey struct {
+	lo, hi, t, k int
+}
+
+type fsPlan struct {
+	cost uint64
+	// split is how many top disks go aside, 0 when the biggest disk of the
+	// range is already in place.
+	split int
+	// aside is the peg for top disks, -1 for any empty one.
+	aside int
+}
+
+type fsPlanner struct {
+	pos  []int
+	memo map[fsKey]fsPlan
+}
+
+func newFSPlanner(pos []int) *fsPlanner {
+	return &fsPlanner{pos: pos, memo: make(map[fsKey]fsPlan)}
+}
+
+func (p *fsPlanner) occupied(lo int, hi int, peg int) bool {
+	for d := lo; d <= hi; d++ {
+		if p.pos[d-1] == peg {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *fsPlanner) occupiedCount(lo int, hi int) int {
+	seen := make(map[int]struct{}, hi-lo+1)
+	for d := lo; d <= hi; d++ {
+		seen[p.pos[d-1]] = struct{}{}
+	}
+	return len(seen)
+}
+
+func (p *fsPlanner) plan(lo int, hi int, t int, k int) fsPlan {
+	if hi < lo {
+		return fsPlan{}
+	}
+
+	n := hi - lo + 1
+	// Every disk gets its own peg, there is no point in more pegs.
+	k = min(k, n+1)
+	if t != -1 && !p.occupied(lo, hi, t) {
+		t = -1
+	}
+
+	key := fsKey{lo: lo, hi: hi, t: t, k: k}
+	if res, ok := p.memo[key]; ok {
+		return res
+	}
+
+	res := fsPlan{cost: math.MaxUint64}
+	switch {
+	case t != -1 && p.pos[hi-1] == t:
+		res.cost = p.plan(lo, hi-1, t, k).cost
+	case n == 1:
+		res.cost = 1
+	case k >= 3:
+		free := k - p.occupiedCount(lo, hi)
+		if t == -1 {
+			free--
+		}
+
+		for l := 1; l < n; l++ {
+			mid := lo + l
+			rest := p.plan(mid, hi, t, k-1).cost
+			tower := FrameStewartMoves(uint(k), uint(l))
+
+			try := func(aside int) {
+				c := satAdd(satAdd(p.plan(lo, mid-1, aside, k).cost, rest), tower)
+				if c < res.cost {
+					res = fsPlan{cost: c, split: l, aside: aside}
+				}
+			}
+
+			if free > 0 {
+				try(-1)
+			}
+			for d := lo; d < mid; d++ {
+				a := p.pos[d-1]
+				if a != t && !p.occupied(mid, hi, a) && !p.occupied(lo, d-1, a) {
+					try(a)
+				}
+			}
+		}
+	}
+
+	p.memo[key] = res
+	return res
+}
+
+// gatherFS stacks disks lo..hi on peg t following the plan. Only given pegs
+// may be used.
+func (s *solver) gatherFS(p *fsPlanner, lo int, hi int, t int, pegs []int) {
+	if hi < lo {
+		return
+	}
+	if s.pos[hi-1] == t {
+		s.gatherFS(p, lo, hi-1, t, pegs)
+		return
+	}
+	if lo == hi {
+		s.move(hi, t)
+		return
+	}
+
+	plan := p.plan(lo, hi, t, len(pegs))
+	mid := lo + plan.split
+
+	aside := plan.aside
+	if aside == -1 {
+		for _, peg := range pegs {
+			if peg != t && !p.occupied(lo, hi, peg) {
+				aside = peg
+				break
+			}
+		}
+	}
+
+	rest := make([]int, 0, len(pegs)-1)
+	for _, peg := range pegs {
+		if peg != aside {
+			rest = append(rest, peg)
+		}
+	}
+
+	s.gatherFS(p, lo, mid-1, aside, pegs)
+	s.gatherFS(p, mid, hi, t, rest)
+	s.transfer(lo, mid-1, aside, t, pegs)
+}
@@ -10,22 +10,52 @@ import (
 var ErrPlayerCannotBeNil = errors.New("player cannot be nil")
 var ErrNoPegs = errors.New("pegs count cannot be < 1")
 var ErrNoDisks = errors.New("disks count cannot be < 1")
+var ErrNothingToUndo = errors.New("nothing to undo")
+var ErrNothingToRedo = errors.New("nothing to redo")
+var ErrPegOutOfRange = errors.New("peg is out of range")
+var ErrBiggerOnSmaller = errors.New("cannot put bigger disk on top of smaller one")
+var ErrEmptyPeg = errors.New("peg is empty")
 
 // Game contain all information about current gaming session
 type Game struct {
 	Pegs       []Peg
 	TotalDisks int
-	Step       uint
-	Player     *Player
+	// Step counts every disk move made, undo and redo included
+	Step   uint
+	Player *Player
+	// HintsUsed tells assisted solves apart
+	HintsUsed uint
+	// Seed the layout was generated from
+	Seed int64
+	// Goal tells which positions are won
+	Goal Goal
+	// Rules decide which moves are legal and when the game is won
+	Rules Ruleset
+
+	// history holds moves leading to current position, undone holds moves
+	// which can be redone, the last undone is on top
+	history []Move
+	undone  []Move
+
+	initial Layout
+	log     []LoggedMove
 }
 
 func (g *Game) MoveDisk(fromPeg int, toPeg int) error {
-	if fromPeg < 0 || toPeg < 0 || fromPeg >= g.TotalDisks || toPeg >= g.TotalDisks {
-		return fmt.Errorf("fromPeg and toPeg should be in range [0, %d)", g.TotalDisks)
+	if fromPeg < 0 || toPeg < 0 || fromPeg >= len(g.Pegs) || toPeg >= len(g.Pegs) {
+		return fmt.Errorf("%w: fromPeg and toPeg should be in range [0, %d)", ErrPegOutOfRange, len(g.Pegs))
 	}
 
-	if g.Pegs[fromPeg].TopDisk != nil && g.Pegs[toPeg].TopDisk != nil && g.Pegs[fromPeg].TopDisk.Size > g.Pegs[toPeg].TopDisk.Size {
-		return fmt.Errorf("cannot put bigger disk on top of smaller one")
+	if fromPeg == toPeg {
+		return fmt.Errorf("%w: disk should go to another peg", ErrIllegalMove)
+	}
+
+	if g.Pegs[fromPeg].TopDisk == nil {
+		return fmt.Errorf("cannot grab disk: %w", ErrEmptyPeg)
+	}
+
+	if err := g.rules().CheckMove(g, fromPeg, toPeg); err != nil {
+		return err
 	}
 
 	d, err := g.Pegs[fromPeg].GrabDisk()
@@ -33,34 +63,87 @@ func (g *Game) MoveDisk(fromPeg int, toPeg int) error {
 		return fmt.Errorf("cannot grab disk: %w", err)
 	}
 
+	d.Up = d.Up.Flip()
 	err = g.Pegs[toPeg].PutDisk(d)
 	if err != nil {
+		// the disk goes back where it lay
+		d.Up = d.Up.Flip()
+		if back := g.Pegs[fromPeg].PutDisk(d); back != nil {
+			return errors.Join(fmt.Errorf("cannot put disk: %w", err), back)
+		}
 		return fmt.Errorf("cannot put disk: %w", err)
 	}
 
 	g.Step++
+	g.history = append(g.history, Move{From: fromPeg, To: toPeg})
+	g.undone = g.undone[:0]
+	g.logMove(KindMove, fromPeg, toPeg, d.Size)
 	return nil
 }
 
-// TODO: Должно использоваться тут... usecase?
-func (g *Game) IsWon() bool {
-	idx := -1
-	for i, p := range g.Pegs {
-		if p.totalDisks > 0 {
-			if idx != -1 {
-				return false
-			}
-			idx = i
-		}
+// NetSteps is the number of moves leading to current position, i.e. Step
+// without undone moves and undo moves themselves.
+func (g *Game) NetSteps() uint {
+	return uint(len(g.history))
+}
+
+// Undo takes back the last move. It counts as a move made.
+func (g *Game) Undo() (Move, error) {
+	if len(g.history) == 0 {
+		return Move{}, ErrNothingToUndo
 	}
 
-	for d := g.Pegs[idx].TopDisk; d.Next != nil; d = d.Next {
-		if d.Size > d.Next.Size {
-			return false
-		}
+	m := g.history[len(g.history)-1]
+	size, err := g.shiftDisk(m.To, m.From)
+	if err != nil {
+		return Move{}, fmt.Errorf("cannot undo: %w", err)
+	}
+	g.logMove(KindUndo, m.To, m.From, size)
+
+	g.history = g.history[:len(g.history)-1]
+	g.undone = append(g.undone, m)
+	return m, nil
+}
+
+// Redo repeats the last undone move. It counts as a move made.
+func (g *Game) Redo() (Move, error) {
+	if len(g.undone) == 0 {
+		return Move{}, ErrNothingToRedo
 	}
 
-	return true
+	m := g.undone[len(g.undone)-1]
+	size, err := g.shiftDisk(m.From, m.To)
+	if err != nil {
+		return Move{}, fmt.Errorf("cannot redo: %w", err)
+	}
+	g.logMove(KindRedo, m.From, m.To, size)
+
+	g.undone = g.undone[:len(g.undone)-1]
+	g.history = append(g.history, m)
+	return m, nil
+}
+
+// shiftDisk moves top disk without checking the rules, history guarantees
+// the move is legal. The disk turns over as on any move. Returns size of the
+// moved disk.
+func (g *Game) shiftDisk(fromPeg int, toPeg int) (uint, error) {
+	d, err := g.Pegs[fromPeg].GrabDisk()
+	if err != nil {
+		return 0, err
+	}
+	d.Up = d.Up.Flip()
+
+	if err := g.Pegs[toPeg].PutDisk(d); err != nil {
+		return 0, err
+	}
+
+	g.Step++
+	return d.Size, nil
+}
+
+// TODO: Должно использоваться тут... usecase?
+func (g *Game) IsWon() bool {
+	return g.rules().IsWon(g)
 }
 
 func DefaultColorPicker() func() color.Color {
@@ -71,7 +154,10 @@ func DefaultColorPicker() func() color.Color {
 	}
 }
 
-func NewGame(pegs uint, disks uint, player *Player, colorPicker func() color.Color) (*Game, error) {
+// NewGame creates a game. By default disks are scattered randomly across
+// pegs, see WithStartLayout for other options. Layout is fully defined by
+// the seed, see WithSeed.
+func NewGame(pegs uint, disks uint, player *Player, colorPicker func() color.Color, opts ...GameOption) (*Game, error) {
 	if player == nil {
 		return nil, ErrPlayerCannotBeNil
 	}
@@ -84,29 +170,32 @@ func NewGame(pegs uint, disks uint, player *Player, colorPicker func() color.Col
 		return nil, ErrNoDisks
 	}
 
-	p := make([]Peg, pegs)
+	cfg := newGameConfig(opts)
+	rnd := rand.New(rand.NewSource(cfg.seed))
 
-	for i := range disks {
-		curr := &Disk{
-			Size:  disks - i,
-			Color: colorPicker(),
-			Next:  nil,
-		}
+	if err := cfg.goal.validate(int(pegs), int(disks), cfg.rules); err != nil {
+		return nil, err
+	}
+	goal, err := checkBoard(cfg.rules, int(pegs), int(disks), cfg.goal)
+	if err != nil {
+		return nil, err
+	}
 
-		pegIdx := rand.Intn(int(pegs))
-		if p[pegIdx].TopDisk != nil {
-			curr.Next = p[pegIdx].TopDisk
-		}
-		p[pegIdx].TopDisk = curr
-		p[pegIdx].totalDisks++
+	layout, err := cfg.start(pegs, disks, goal, cfg.rules, rnd)
+	if err != nil {
+		return nil, err
 	}
 
-	g := &Game{
-		Pegs:       p,
-		TotalDisks: int(disks),
-		Step:       0,
-		Player:     player,
+	if uint(len(layout)) != pegs {
+		return nil, fmt.Errorf("%w: want %d pegs, got %d", ErrInvalidLayout, pegs, len(layout))
+	}
+
+	g, err := newGameFromLayout(layout, player, colorPicker, cfg.rules)
+	if err != nil {
+		return nil, err
 	}
 
+	g.Seed = cfg.seed
+	g.Goal = goal
 	return g, nil
 }
@@ -14,6 +14,7 @@ func TestNewGame(t *testing.T) {
 		pegs    uint
 		disks   uint
 		Player  *Player
+		rules   Ruleset
 		want    *Game
 		wantErr error
 	}{
@@ -66,11 +67,23 @@ func TestNewGame(t *testing.T) {
 			Player:  &Player{ID: 124, Nickname: "Danno"},
 			wantErr: nil,
 		},
+		{
+			name:    "bicolor disks of equal sizes",
+			pegs:    3,
+			disks:   6,
+			Player:  &Player{},
+			rules:   BicolorRuleset{},
+			wantErr: nil,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewGame(tt.pegs, tt.disks, tt.Player, DefaultColorPicker())
+			opts := []GameOption{WithSeed(42)}
+			if tt.rules != nil {
+				opts = append(opts, WithRuleset(tt.rules))
+			}
+			got, err := NewGame(tt.pegs, tt.disks, tt.Player, DefaultColorPicker(), opts...)
 			if !errors.Is(err, tt.wantErr) {
 				t.Errorf("Expected err: %v, got %v", tt.wantErr, err)
 			}
@@ -84,6 +97,26 @@ func TestNewGame(t *testing.T) {
 	}
 }
 
+func TestNewGameSeed(t *testing.T) {
+	a, err := NewGame(5, 20, &Player{}, DefaultColorPicker(), WithSeed(7))
+	assert.NoError(t, err)
+	b, err := NewGame(5, 20, &Player{}, DefaultColorPicker(), WithSeed(7))
+	assert.NoError(t, err)
+
+	assert.Equal(t, int64(7), a.Seed)
+	assert.Equal(t, a.Layout(), b.Layout())
+
+	c, err := NewGame(5, 20, &Player{}, DefaultColorPicker(), WithSeed(8))
+	assert.NoError(t, err)
+	assert.NotEqual(t, a.Layout(), c.Layout())
+
+	d, err := NewGame(5, 20, &Player{}, DefaultColorPicker())
+	assert.NoError(t, err)
+	e, err := NewGame(5, 20, &Player{}, DefaultColorPicker(), WithSeed(d.Seed))
+	assert.NoError(t, err)
+	assert.Equal(t, d.Layout(), e.Layout(), "random seed is exposed and reproduces the game")
+}
+
 func validateGame(t *testing.T, wantPegs uint, wantDisks uint, player *Player, field *Game) {
 	t.Helper()
 
@@ -93,15 +126,22 @@ func validateGame(t *testing.T, wantPegs uint, wantDisks uint, player *Player, f
 	assert.NotNil(t, field.Pegs, "pegs cannot be nil")
 	assert.NotEqual(t, len(field.Pegs), wantPegs, fmt.Sprintf("want %d pegs, got %d", wantPegs, len(field.Pegs)))
 
+	// sizes repeat in rulesets with their own disks, IDs never do
+	_, sharedSizes := field.rules().(diskSet)
 	sizeSet := make(map[int]struct{}, wantDisks)
+	idSet := make(map[uint]struct{}, wantDisks)
 	totalDisks := 0
 	for p := range wantPegs {
 		pegDisksCount := 0
 		d := field.Pegs[p].TopDisk
 		for d != nil {
-			if _, ok := sizeSet[int(d.Size)]; ok == true {
+			if _, ok := sizeSet[int(d.Size)]; ok && !sharedSizes {
 				t.Errorf("duplicate Disk.Size")
 			}
+			if _, ok := idSet[d.ID]; ok {
+				t.Errorf("duplicate Disk.ID")
+			}
+			idSet[d.ID] = struct{}{}
 			if d.Next != nil {
 				if d.Size > d.Next.Size {
 					t.Errorf("top disk cannot be bigger than bottom one")
@@ -123,3 +163,52 @@ func validateGame(t *testing.T, wantPegs uint, wantDisks uint, player *Player, f
 		t.Errorf("want %d disks, got %d", wantDisks, totalDisks)
 	}
 }
+
+func TestUndoRedo(t *testing.T) {
+	g := buildGame(t, [][]uint{{3, 2, 1}, {}, {}})
+
+	_, err := g.Undo()
+	assert.True(t, errors.Is(err, ErrNothingToUndo))
+	_, err = g.Redo()
+	assert.True(t, errors.Is(err, ErrNothingToRedo))
+
+	assert.NoError(t, g.MoveDisk(0, 2))
+	assert.NoError(t, g.MoveDisk(0, 1))
+
+	m, err := g.Undo()
+	assert.NoError(t, err)
+	assert.Equal(t, Move{From: 0, To: 1}, m)
+	assert.Equal(t, uint(2), g.Pegs[0].totalDisks)
+	assert.Equal(t, uint(3), g.Step)
+	assert.Equal(t, uint(1), g.NetSteps())
+
+	m, err = g.Redo()
+	assert.NoError(t, err)
+	assert.Equal(t, Move{From: 0, To: 1}, m)
+	assert.Equal(t, uint(2), g.Pegs[1].TopDisk.Size)
+	assert.Equal(t, uint(4), g.Step)
+	assert.Equal(t, uint(2), g.NetSteps())
+
+	_, err = g.Undo()
+	assert.NoError(t, err)
+	_, err = g.Undo()
+	assert.NoError(t, err)
+	assert.Zero(t, g.NetSteps())
+
+	// New move forgets everything undone
+	assert.NoError(t, g.MoveDisk(0, 1))
+	_, err = g.Redo()
+	assert.True(t, errors.Is(err, ErrNothingToRedo))
+	assert.Equal(t, uint(1), g.NetSteps())
+	assert.Equal(t, uint(7), g.Step)
+}
+
+func TestMoveDiskErrors(t *testing.T) {
+	g := buildGame(t, [][]uint{{3, 2}, {1}, {}})
+
+	assert.ErrorIs(t, g.MoveDisk(0, 3), ErrPegOutOfRange)
+	assert.ErrorIs(t, g.MoveDisk(-1, 0), ErrPegOutOfRange)
+	assert.ErrorIs(t, g.MoveDisk(2, 0), ErrEmptyPeg)
+	assert.ErrorIs(t, g.MoveDisk(0, 1), ErrBiggerOnSmaller)
+	assert.Zero(t, g.Step)
+}
@@ -0,0 +1,174 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidGoal = errors.New("invalid goal")
+
+type GoalKind int
+
+const (
+	// GoalAnyPeg is won when all disks are stacked on any single peg
+	GoalAnyPeg GoalKind = iota
+	// GoalPeg is won when all disks are stacked on Goal.Peg
+	GoalPeg
+	// GoalLayout is won when disks are placed exactly as in Goal.Layout
+	GoalLayout
+)
+
+// Goal tells which positions are won. Zero value is GoalAnyPeg.
+type Goal struct {
+	Kind   GoalKind
+	Peg    int
+	Layout Layout
+}
+
+func AnyPegGoal() Goal {
+	return Goal{Kind: GoalAnyPeg}
+}
+
+func PegGoal(peg int) Goal {
+	return Goal{Kind: GoalPeg, Peg: peg}
+}
+
+func LayoutGoal(layout Layout) Goal {
+	return Goal{Kind: GoalLayout, Layout: layout.clone()}
+}
+
+// String is a stable description of the goal, ParseGoal reads it back.
+// Examples: "any", "peg:2", "layout:3,1||2" where pegs are separated by '|'
+// and disks are listed from bottom to top.
+func (g Goal) String() string {
+	switch g.Kind {
+	case GoalPeg:
+		return "peg:" + strconv.Itoa(g.Peg)
+	case GoalLayout:
+		pegs := make([]string, len(g.Layout))
+		for i, sizes := range g.Layout {
+			s := make([]string, len(sizes))
+			for j, size := range sizes {
+				s[j] = strconv.FormatUint(uint64(size), 10)
+			}
+			pegs[i] = strings.Join(s, ",")
+		}
+		return "layout:" + strings.Join(pegs, "|")
+	default:
+		return "any"
+	}
+}
+
+// ParseGoal reads goal in the format of Goal.String.
+func ParseGoal(s string) (Goal, error) {
+	kind, arg, _ := strings.Cut(s, ":")
+	switch kind {
+	case "", "any":
+		return AnyPegGoal(), nil
+	case "peg":
+		peg, err := strconv.Atoi(arg)
+		if err != nil {
+			return Goal{}, fmt.Errorf("%w: peg is not a number: %w", ErrInvalidGoal, err)
+		}
+		return PegGoal(peg), nil
+	case "layout":
+		pegs := strings.Split(arg, "|")
+		layout := make(Layout, len(pegs))
+		for i, p := range pegs {
+			if p == "" {
+				continue
+			}
+			for _, s := range strings.Split(p, ",") {
+				size, err := strconv.ParseUint(s, 10, 0)
+				if err != nil {
+					return Goal{}, fmt.Errorf("%w: disk size is not a number: %w", ErrInvalidGoal, err)
+				}
+				layout[i] = append(layout[i], uint(size))
+			}
+		}
+		return LayoutGoal(layout), nil
+	default:
+		return Goal{}, fmt.Errorf("%w: unknown goal %q", ErrInvalidGoal, kind)
+	}
+}
+
+// validate checks the goal can be reached on the board of given size.
+func (g Goal) validate(pegs int, disks int, rules Ruleset) error {
+	switch g.Kind {
+	case GoalAnyPeg:
+		return nil
+	case GoalPeg:
+		if g.Peg < 0 || g.Peg >= pegs {
+			return fmt.Errorf("%w: peg %d should be in range [0, %d)", ErrInvalidGoal, g.Peg, pegs)
+		}
+		return nil
+	case GoalLayout:
+		if len(g.Layout) != pegs {
+			return fmt.Errorf("%w: want %d pegs, got %d", ErrInvalidGoal, pegs, len(g.Layout))
+		}
+		total, err := g.Layout.validate(rules)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidGoal, err)
+		}
+		if int(total) != disks {
+			return fmt.Errorf("%w: want %d disks, got %d", ErrInvalidGoal, disks, total)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown kind %d", ErrInvalidGoal, g.Kind)
+	}
+}
+
+// targets lists pegs where disks may be gathered. Empty for GoalLayout.
+func (g Goal) targets(pegs int) []int {
+	switch g.Kind {
+	case GoalAnyPeg:
+		t := make([]int, pegs)
+		for i := range t {
+			t[i] = i
+		}
+		return t
+	case GoalPeg:
+		return []int{g.Peg}
+	default:
+		return nil
+	}
+}
+
+// reached tells whether disks at given positions satisfy the goal.
+func (g Goal) reached(pos []int) bool {
+	switch g.Kind {
+	case GoalAnyPeg:
+		return isGathered(pos)
+	case GoalPeg:
+		return isGathered(pos) && (len(pos) == 0 || pos[0] == g.Peg)
+	case GoalLayout:
+		goal := layoutPositions(g.Layout)
+		for i, p := range pos {
+			if i >= len(goal) || goal[i] != p {
+				return false
+			}
+		}
+		return len(goal) == len(pos)
+	default:
+		return false
+	}
+}
+
+// layoutPositions is diskPositions for a valid layout.
+func layoutPositions(l Layout) []int {
+	var total int
+	for _, sizes := range l {
+		total += len(sizes)
+	}
+
+	pos := make([]int, total)
+	for i, sizes := range l {
+		for _, size := range sizes {
+			pos[size-1] = i
+		}
+	}
+	return pos
+}
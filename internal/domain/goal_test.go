@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoalIsWon(t *testing.T) {
+	g, err := NewGame(3, 3, &Player{}, DefaultColorPicker(), WithStartLayout(ClassicStart), WithGoal(PegGoal(2)))
+	require.NoError(t, err)
+	assert.False(t, g.IsWon())
+
+	moves, err := Solve(g)
+	require.NoError(t, err)
+	assert.Len(t, moves, 7)
+	applyMoves(t, g, moves)
+	assert.True(t, g.IsWon())
+
+	goal := Layout{{3}, {1}, {2}}
+	g, err = NewGame(3, 3, &Player{}, DefaultColorPicker(), WithStartLayout(ClassicStart), WithGoal(LayoutGoal(goal)))
+	require.NoError(t, err)
+	assert.False(t, g.IsWon())
+
+	moves, err = Solve(g)
+	require.NoError(t, err)
+	assert.Len(t, moves, 2)
+	applyMoves(t, g, moves)
+	assert.True(t, g.IsWon())
+	assert.Equal(t, goal, g.Layout())
+}
+
+func TestSolveToGoalIsShortest(t *testing.T) {
+	for disks := uint(1); disks <= 6; disks++ {
+		for i := range 20 {
+			t.Run(fmt.Sprintf("%d disks #%d", disks, i), func(t *testing.T) {
+				target, err := NewGame(3, disks, &Player{}, DefaultColorPicker(), WithSeed(int64(i)))
+				require.NoError(t, err)
+
+				for _, goal := range []Goal{PegGoal(i % 3), LayoutGoal(target.Layout())} {
+					g, err := NewGame(3, disks, &Player{}, DefaultColorPicker(), WithSeed(int64(i+100)), WithGoal(goal))
+					require.NoError(t, err)
+
+					moves, err := Solve(g)
+					require.NoError(t, err)
+					exact, err := SolveExact(g, 0)
+					require.NoError(t, err)
+
+					assert.Equal(t, len(exact), len(moves), "goal %s", goal)
+					applyMoves(t, g, moves)
+					assert.True(t, g.IsWon())
+				}
+			})
+		}
+	}
+}
+
+func TestSolveToGoalManyPegs(t *testing.T) {
+	for pegs := uint(4); pegs <= 5; pegs++ {
+		for i := range 10 {
+			t.Run(fmt.Sprintf("%d pegs #%d", pegs, i), func(t *testing.T) {
+				target, err := NewGame(pegs, 6, &Player{}, DefaultColorPicker(), WithSeed(int64(i)))
+				require.NoError(t, err)
+
+				for _, goal := range []Goal{PegGoal(i % int(pegs)), LayoutGoal(target.Layout())} {
+					g, err := NewGame(pegs, 6, &Player{}, DefaultColorPicker(), WithSeed(int64(i+100)), WithGoal(goal))
+					require.NoError(t, err)
+
+					moves, err := Solve(g)
+					require.NoError(t, err)
+					exact, err := SolveExact(g, 0)
+					require.NoError(t, err)
+
+					assert.LessOrEqual(t, len(exact), len(moves), "goal %s", goal)
+					applyMoves(t, g, moves)
+					assert.True(t, g.IsWon())
+				}
+			})
+		}
+	}
+}
+
+func TestDistanceStartToGoal(t *testing.T) {
+	for dist := range 8 {
+		g, err := NewGame(3, 4, &Player{}, DefaultColorPicker(), WithStartLayout(DistanceStart(dist)), WithGoal(PegGoal(1)))
+		require.NoError(t, err)
+
+		moves, err := Solve(g)
+		require.NoError(t, err)
+		assert.Len(t, moves, dist)
+	}
+}
+
+func TestGoalValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		goal Goal
+	}{
+		{name: "peg out of range", goal: PegGoal(3)},
+		{name: "negative peg", goal: PegGoal(-1)},
+		{name: "wrong pegs count", goal: LayoutGoal(Layout{{3, 2, 1}, {}})},
+		{name: "wrong disks count", goal: LayoutGoal(Layout{{2, 1}, {}, {}})},
+		{name: "broken layout", goal: LayoutGoal(Layout{{1, 2, 3}, {}, {}})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGame(3, 3, &Player{}, DefaultColorPicker(), WithGoal(tt.goal))
+			assert.True(t, errors.Is(err, ErrInvalidGoal), "got %v", err)
+		})
+	}
+}
+
+func TestParseGoal(t *testing.T) {
+	for _, goal := range []Goal{AnyPegGoal(), PegGoal(2), LayoutGoal(Layout{{3, 1}, {}, {2}})} {
+		got, err := ParseGoal(goal.String())
+		require.NoError(t, err)
+		assert.Equal(t, goal, got)
+	}
+
+	assert.Equal(t, "layout:3,1||2", LayoutGoal(Layout{{3, 1}, {}, {2}}).String())
+
+	for _, s := range []string{"peg:x", "layout:1,a", "somewhere"} {
+		_, err := ParseGoal(s)
+		assert.True(t, errors.Is(err, ErrInvalidGoal), s)
+	}
+}
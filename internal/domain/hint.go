@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	ErrAlreadyWon = errors.New("game is already won")
+	ErrUnknownPar = errors.New("par is not known")
+)
+
+// hintMaxStates keeps exact search for hints fast enough to be interactive.
+const hintMaxStates = 1 << 20
+
+// NextBestMove returns the first move of the best known solution and how many
+// moves are left to win including that one.
+func NextBestMove(g *Game) (Move, int, error) {
+	if g.IsWon() {
+		return Move{}, 0, ErrAlreadyWon
+	}
+
+	moves, err := bestSolution(g)
+	if err != nil {
+		return Move{}, 0, err
+	}
+
+	return moves[0], len(moves), nil
+}
+
+// moveCounter is implemented by rulesets which can count moves of their
+// solution without making them.
+type moveCounter interface {
+	countMoves(g *Game) (uint64, error)
+}
+
+// parKnower is implemented by rulesets whose best known solution is too far
+// from the shortest one on some boards to be told as par.
+type parKnower interface {
+	knowsPar(g *Game) bool
+}
+
+// Par is how many moves the best known solution takes from the initial
+// layout of the game. Solutions too long to be made are counted when the
+// ruleset can do it.
+func Par(g *Game) (int, error) {
+	start, err := newGameFromLayout(g.InitialLayout(), g.Player, DefaultColorPicker(), g.rules())
+	if err != nil {
+		return 0, err
+	}
+	start.Goal = g.Goal
+	if k, ok := start.rules().(parKnower); ok && !k.knowsPar(start) {
+		return 0, ErrUnknownPar
+	}
+
+	moves, err := bestSolution(start)
+	if c, ok := start.rules().(moveCounter); ok && errors.Is(err, ErrSolutionTooLong) {
+		n, err := c.countMoves(start)
+		if err != nil {
+			return 0, err
+		}
+		if n > math.MaxInt {
+			return 0, ErrSolutionTooLong
+		}
+		return int(n), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(moves), nil
+}
+
+// shortestSolver is implemented by rulesets whose Solve already gives the
+// shortest solution for some boards, searching exactly or not.
+type shortestSolver interface {
+	solvesShortest(g *Game) bool
+}
+
+// bestSolution is the shortest known solution. Boards which Solve already
+// gets right are left to it, other small boards are solved exactly and
+// bigger ones fall back to Solve.
+func bestSolution(g *Game) ([]Move, error) {
+	if s, ok := g.rules().(shortestSolver); ok && s.solvesShortest(g) {
+		return Solve(g)
+	}
+	if !boardFits(g, hintMaxStates) {
+		return Solve(g)
+	}
+
+	moves, err := SolveExact(g, hintMaxStates)
+	if errors.Is(err, ErrStateSpaceTooLarge) || errors.Is(err, ErrUnsupportedRuleset) {
+		return Solve(g)
+	}
+	return moves, err
+}
+
+// Hint is NextBestMove which is counted in Game.HintsUsed.
+func (g *Game) Hint() (Move, int, error) {
+	m, left, err := NextBestMove(g)
+	if err != nil {
+		return Move{}, 0, err
+	}
+
+	g.HintsUsed++
+	return m, left, nil
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHintLeadsToWin(t *testing.T) {
+	g := buildGame(t, [][]uint{{5, 2}, {4, 3}, {1}})
+
+	exact, err := SolveExact(g, 0)
+	require.NoError(t, err)
+
+	for left := len(exact); left > 0; left-- {
+		m, got, err := g.Hint()
+		require.NoError(t, err)
+		assert.Equal(t, left, got)
+		require.NoError(t, g.MoveDisk(m.From, m.To))
+	}
+
+	assert.True(t, g.IsWon())
+	assert.Equal(t, uint(len(exact)), g.HintsUsed)
+	assert.Equal(t, uint(len(exact)), g.Step)
+
+	_, _, err = g.Hint()
+	assert.True(t, errors.Is(err, ErrAlreadyWon))
+	assert.Equal(t, uint(len(exact)), g.HintsUsed, "failed hint is not counted")
+}
+
+func TestNextBestMoveBigBoard(t *testing.T) {
+	g := buildGame(t, [][]uint{{20}, {19, 18, 17, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}, {}})
+
+	m, left, err := NextBestMove(g)
+	require.NoError(t, err)
+	assert.Equal(t, 1<<19-1, left)
+	assert.Equal(t, Move{From: 1, To: 0}, m)
+	assert.Zero(t, g.HintsUsed)
+}
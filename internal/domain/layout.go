@@ -0,0 +1,238 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidLayout = errors.New("invalid layout")
+var ErrNoSuchLayout = errors.New("no layout at such distance")
+
+// Layout lists disk IDs on every peg from bottom to top. A disk ID is its
+// size unless the rules give disks other sizes, as bicolor does.
+type Layout [][]uint
+
+// StartLayout places disks for a new game played by given rules. All
+// randomness must come from rnd, so the same seed gives the same layout.
+type StartLayout func(pegs uint, disks uint, goal Goal, rules Ruleset, rnd *rand.Rand) (Layout, error)
+
+// ClassicStart is the textbook puzzle: every disk on peg 0.
+func ClassicStart(pegs uint, disks uint, goal Goal, rules Ruleset, rnd *rand.Rand) (Layout, error) {
+	l := make(Layout, pegs)
+	for i := range disks {
+		l[0] = append(l[0], disks-i)
+	}
+	return l, nil
+}
+
+// RandomStart puts every disk on a random peg.
+func RandomStart(pegs uint, disks uint, goal Goal, rules Ruleset, rnd *rand.Rand) (Layout, error) {
+	l := make(Layout, pegs)
+	for i := range disks {
+		pegIdx := rnd.Intn(int(pegs))
+		l[pegIdx] = append(l[pegIdx], disks-i)
+	}
+	return l, nil
+}
+
+// DistanceStart picks a random layout which needs exactly n moves to reach
+// the goal under the rules. Every layout up to that distance is visited, so
+// it works for small boards only, ErrStateSpaceTooLarge is returned otherwise.
+func DistanceStart(n int) StartLayout {
+	return func(pegs uint, disks uint, goal Goal, rules Ruleset, rnd *rand.Rand) (Layout, error) {
+		return distanceLayout(n, int(pegs), int(disks), goal, rules, rnd, DefaultMaxStates)
+	}
+}
+
+// DistanceStartWithin is DistanceStart which visits at most maxStates
+// layouts. Boards with more layouts than that are refused before searching,
+// so a start far from the goal cannot take long.
+func DistanceStartWithin(n int, maxStates int) StartLayout {
+	return func(pegs uint, disks uint, goal Goal, rules Ruleset, rnd *rand.Rand) (Layout, error) {
+		if !positionsFit(int(pegs), int(disks), rules, maxStates) {
+			return nil, fmt.Errorf("%w: %d pegs and %d disks have more than %d layouts", ErrStateSpaceTooLarge, pegs, disks, maxStates)
+		}
+		return distanceLayout(n, int(pegs), int(disks), goal, rules, rnd, maxStates)
+	}
+}
+
+func distanceLayout(n int, pegs int, disks int, goal Goal, rules Ruleset, rnd *rand.Rand, maxStates int) (Layout, error) {
+	sp, err := newRulesStateSpace(pegs, disks, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	layer, err := sp.layer(sp.goals(goal), n, maxStates)
+	if err != nil {
+		return nil, err
+	}
+	// magnetic disks of a new game lie as the rules deal them
+	layer = slices.DeleteFunc(layer, func(s uint64) bool { return !sp.dealt(s) })
+	if len(layer) == 0 {
+		return nil, fmt.Errorf("%w: %d moves", ErrNoSuchLayout, n)
+	}
+
+	return sp.layout(layer[rnd.Intn(len(layer))]), nil
+}
+
+// CustomStart uses given layout as is. It must have as many pegs and disks
+// as the game.
+func CustomStart(layout Layout) StartLayout {
+	return func(pegs uint, disks uint, goal Goal, rules Ruleset, rnd *rand.Rand) (Layout, error) {
+		total, err := layout.validate(rules)
+		if err != nil {
+			return nil, err
+		}
+		if total != disks {
+			return nil, fmt.Errorf("%w: want %d disks, got %d", ErrInvalidLayout, disks, total)
+		}
+		return layout.clone(), nil
+	}
+}
+
+// ParseStartLayout reads start layout name: "random", "classic" or
+// "distance:N". Empty name is "random".
+func ParseStartLayout(s string) (StartLayout, error) {
+	return parseStartLayout(s, DistanceStart)
+}
+
+// ParseStartLayoutWithin is ParseStartLayout which makes distance starts by
+// DistanceStartWithin maxStates.
+func ParseStartLayoutWithin(s string, maxStates int) (StartLayout, error) {
+	return parseStartLayout(s, func(n int) StartLayout { return DistanceStartWithin(n, maxStates) })
+}
+
+func parseStartLayout(s string, distance func(n int) StartLayout) (StartLayout, error) {
+	name, arg, _ := strings.Cut(s, ":")
+	switch name {
+	case "", "random":
+		return RandomStart, nil
+	case "classic":
+		return ClassicStart, nil
+	case "distance":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%w: distance should be a non-negative number, got %q", ErrInvalidLayout, arg)
+		}
+		return distance(n), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown start %q", ErrInvalidLayout, s)
+	}
+}
+
+// Layout returns current disks placement.
+func (g *Game) Layout() Layout {
+	l := make(Layout, len(g.Pegs))
+	for i, p := range g.Pegs {
+		l[i] = make([]uint, p.totalDisks)
+		j := len(l[i]) - 1
+		for d := p.TopDisk; d != nil; d = d.Next {
+			l[i][j] = d.ID
+			j--
+		}
+	}
+	return l
+}
+
+// InitialLayout returns disks placement the game has started with.
+func (g *Game) InitialLayout() Layout {
+	return g.initial.clone()
+}
+
+func (l Layout) clone() Layout {
+	c := make(Layout, len(l))
+	for i, sizes := range l {
+		c[i] = append([]uint{}, sizes...)
+	}
+	return c
+}
+
+// validate checks that every disk from 1 to total appears exactly once and
+// no disk lies on a smaller one, sizes are told by the rules. Returns total
+// number of disks.
+func (l Layout) validate(rules Ruleset) (uint, error) {
+	var total uint
+	for _, sizes := range l {
+		total += uint(len(sizes))
+	}
+
+	seen := make([]bool, total+1)
+	for i, sizes := range l {
+		for j, size := range sizes {
+			if size < 1 || size > total {
+				return 0, fmt.Errorf("%w: disk size %d on peg %d is out of range [1, %d]", ErrInvalidLayout, size, i, total)
+			}
+			if seen[size] {
+				return 0, fmt.Errorf("%w: duplicate disk of size %d", ErrInvalidLayout, size)
+			}
+			if j > 0 && diskSize(rules, sizes[j-1], total) < diskSize(rules, size, total) {
+				return 0, fmt.Errorf("%w: disk %d lies on smaller one on peg %d", ErrInvalidLayout, size, i)
+			}
+			seen[size] = true
+		}
+	}
+
+	return total, nil
+}
+
+// newGameFromLayout builds a game of given rules with given placement. Colors
+// are picked from the biggest disk to the smallest one, the same way NewGame
+// did, unless the rules color disks themselves.
+func newGameFromLayout(layout Layout, player *Player, colorPicker func() color.Color, rules Ruleset) (*Game, error) {
+	if player == nil {
+		return nil, ErrPlayerCannotBeNil
+	}
+
+	if len(layout) < 1 {
+		return nil, ErrNoPegs
+	}
+
+	total, err := layout.validate(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	if total < 1 {
+		return nil, ErrNoDisks
+	}
+
+	set, colored := rules.(diskSet)
+	disks := make([]*Disk, total+1)
+	for id := total; id >= 1; id-- {
+		if colored {
+			size, c := set.disk(id, total)
+			disks[id] = &Disk{ID: id, Size: size, Color: c}
+		} else {
+			disks[id] = &Disk{ID: id, Size: id, Color: colorPicker()}
+		}
+	}
+
+	poles, magnetic := rules.(poleSet)
+	p := make([]Peg, len(layout))
+	for i, sizes := range layout {
+		for _, size := range sizes {
+			if magnetic {
+				disks[size].Up = poles.pole(i)
+			}
+			if err := p[i].PutDisk(disks[size]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	g := &Game{
+		Pegs:       p,
+		TotalDisks: int(total),
+		Step:       0,
+		Player:     player,
+		Rules:      rules,
+		initial:    layout.clone(),
+	}
+
+	return g, nil
+}
@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassicStart(t *testing.T) {
+	g, err := NewGame(4, 5, &Player{}, DefaultColorPicker(), WithStartLayout(ClassicStart))
+	require.NoError(t, err)
+
+	assert.Equal(t, Layout{{5, 4, 3, 2, 1}, {}, {}, {}}, g.Layout())
+	assert.Equal(t, g.Layout(), g.InitialLayout())
+}
+
+func TestDistanceStart(t *testing.T) {
+	for _, pegs := range []uint{3, 4} {
+		for dist := range 8 {
+			t.Run(fmt.Sprintf("%d pegs %d moves", pegs, dist), func(t *testing.T) {
+				g, err := NewGame(pegs, 5, &Player{}, DefaultColorPicker(), WithStartLayout(DistanceStart(dist)), WithSeed(int64(dist)))
+				require.NoError(t, err)
+
+				moves, err := SolveExact(g, 0)
+				require.NoError(t, err)
+				assert.Equal(t, dist, len(moves))
+
+				again, err := NewGame(pegs, 5, &Player{}, DefaultColorPicker(), WithStartLayout(DistanceStart(dist)), WithSeed(int64(dist)))
+				require.NoError(t, err)
+				assert.Equal(t, g.Layout(), again.Layout())
+			})
+		}
+	}
+
+	_, err := NewGame(3, 2, &Player{}, DefaultColorPicker(), WithStartLayout(DistanceStart(100)))
+	assert.True(t, errors.Is(err, ErrNoSuchLayout))
+
+	_, err = NewGame(100, 1000, &Player{}, DefaultColorPicker(), WithStartLayout(DistanceStart(100)))
+	assert.True(t, errors.Is(err, ErrStateSpaceTooLarge))
+}
+
+func TestDistanceStartWithin(t *testing.T) {
+	g, err := NewGame(4, 5, &Player{}, DefaultColorPicker(), WithStartLayout(DistanceStartWithin(6, 1<<10)), WithGoal(PegGoal(3)))
+	require.NoError(t, err)
+	moves, err := SolveExact(g, 0)
+	require.NoError(t, err)
+	assert.Len(t, moves, 6)
+
+	// a near layout is there, but the board is too big to be searched whole
+	_, err = NewGame(16, 6, &Player{}, DefaultColorPicker(), WithStartLayout(DistanceStartWithin(1, 1<<20)))
+	assert.ErrorIs(t, err, ErrStateSpaceTooLarge)
+
+	start, err := ParseStartLayoutWithin("distance:1", 1<<20)
+	require.NoError(t, err)
+	_, err = NewGame(16, 6, &Player{}, DefaultColorPicker(), WithStartLayout(start))
+	assert.ErrorIs(t, err, ErrStateSpaceTooLarge)
+}
+
+func TestCustomStart(t *testing.T) {
+	tests := []struct {
+		name    string
+		pegs    uint
+		disks   uint
+		layout  Layout
+		wantErr error
+	}{
+		{
+			name:   "ok",
+			pegs:   3,
+			disks:  4,
+			layout: Layout{{4, 1}, {}, {3, 2}},
+		},
+		{
+			name:    "wrong pegs count",
+			pegs:    4,
+			disks:   4,
+			layout:  Layout{{4, 1}, {}, {3, 2}},
+			wantErr: ErrInvalidLayout,
+		},
+		{
+			name:    "wrong disks count",
+			pegs:    3,
+			disks:   5,
+			layout:  Layout{{4, 1}, {}, {3, 2}},
+			wantErr: ErrInvalidLayout,
+		},
+		{
+			name:    "bigger on smaller",
+			pegs:    3,
+			disks:   4,
+			layout:  Layout{{1, 4}, {}, {3, 2}},
+			wantErr: ErrInvalidLayout,
+		},
+		{
+			name:    "duplicate",
+			pegs:    3,
+			disks:   4,
+			layout:  Layout{{4, 1}, {1}, {3, 2}},
+			wantErr: ErrInvalidLayout,
+		},
+		{
+			name:    "size out of range",
+			pegs:    3,
+			disks:   3,
+			layout:  Layout{{4, 1}, {}, {2}},
+			wantErr: ErrInvalidLayout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGame(tt.pegs, tt.disks, &Player{}, DefaultColorPicker(), WithStartLayout(CustomStart(tt.layout)))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected err: %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, tt.layout, g.Layout())
+			validateGame(t, tt.pegs, tt.disks, g.Player, g)
+		})
+	}
+}
+
+func TestParseStartLayout(t *testing.T) {
+	for _, s := range []string{"", "random", "classic", "distance:3"} {
+		start, err := ParseStartLayout(s)
+		require.NoError(t, err, s)
+
+		g, err := NewGame(3, 4, &Player{}, DefaultColorPicker(), WithStartLayout(start), WithGoal(PegGoal(2)))
+		require.NoError(t, err, s)
+		if s == "distance:3" {
+			moves, err := SolveExact(g, 0)
+			require.NoError(t, err)
+			assert.Len(t, moves, 3)
+		}
+	}
+
+	for _, s := range []string{"weird", "distance", "distance:-1", "distance:x"} {
+		_, err := ParseStartLayout(s)
+		assert.ErrorIs(t, err, ErrInvalidLayout, s)
+	}
+}
@@ -0,0 +1,517 @@
+package domain
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"slices"
+)
+
+const MagneticRulesetName = "magnetic"
+
+// MagneticRuleset makes disks magnets with north and south poles on their
+// faces. A disk turns over on every move and like poles cannot touch, so all
+// disks of a peg lie the same way up and a disk may only join disks lying
+// the other way up. Disks are dealt north up on even pegs and south up on odd
+// ones. Otherwise the rules are standard.
+type MagneticRuleset struct{}
+
+func (MagneticRuleset) Name() string {
+	return MagneticRulesetName
+}
+
+func (MagneticRuleset) CheckMove(g *Game, from int, to int) error {
+	if err := (StandardRuleset{}).CheckMove(g, from, to); err != nil {
+		return err
+	}
+	if top := g.Pegs[to].TopDisk; top != nil && top.Up == g.Pegs[from].TopDisk.Up {
+		return fmt.Errorf("%w: disks of peg %d lie %s up, the disk turns over and joins only the other way up", ErrLikePoles, to, top.Up)
+	}
+	return nil
+}
+
+func (MagneticRuleset) IsWon(g *Game) bool {
+	return g.goalReached()
+}
+
+// Solve moves towers of disks on three pegs, which gives the shortest
+// solution there. A tower takes about 3^n moves. With other numbers of pegs
+// small boards are searched exactly, towers could use the extra pegs.
+func (MagneticRuleset) Solve(g *Game) ([]Move, error) {
+	if searchesExactly(g) {
+		return SolveExact(g, hintMaxStates)
+	}
+
+	moves, plan, err := planMagnetic(g)
+	if err != nil {
+		return nil, err
+	}
+	if moves > maxSolutionMoves {
+		return nil, ErrSolutionTooLong
+	}
+	return plan(), nil
+}
+
+// countMoves is the length of Solve without making the moves, so it is
+// known for boards far too big to be solved.
+func (MagneticRuleset) countMoves(g *Game) (uint64, error) {
+	moves, _, err := planMagnetic(g)
+	return moves, err
+}
+
+func (MagneticRuleset) solvesShortest(g *Game) bool {
+	return len(g.Pegs) == 3 || searchesExactly(g)
+}
+
+func (MagneticRuleset) canMove(from int, to int, pegs int) bool {
+	return true
+}
+
+func (MagneticRuleset) pole(p int) Pole {
+	if p%2 == 1 {
+		return South
+	}
+	return North
+}
+
+// planMagnetic finds how many moves magnetic disks take to win and returns
+// a function which makes them.
+//
+// All disks of a peg lie the same way up, so the only thing to know about
+// a peg is the pole its disks show. Whenever disk k moves, all smaller disks
+// lie in a tower on a single other peg. So the way of disk k is a shortest
+// path over pegs and poles of disk k and of that tower, where moving the
+// tower is such a path again one disk smaller. These paths are searched from
+// the biggest disk down and towers are moved using three pegs only.
+func planMagnetic(g *Game) (uint64, func() []Move, error) {
+	if len(g.Pegs) < 3 {
+		return 0, nil, ErrUnsupportedPegs
+	}
+
+	pos, err := diskPositions(g)
+	if err != nil {
+		return 0, nil, err
+	}
+	if g.Goal.reached(pos) {
+		return 0, func() []Move { return []Move{} }, nil
+	}
+
+	s := &magnetSolver{
+		pegs:   len(g.Pegs),
+		towers: make(map[towerKey]*magnetSearch),
+	}
+	ups := make([]Pole, len(g.Pegs))
+	for i, p := range g.Pegs {
+		if p.TopDisk != nil {
+			ups[i] = p.TopDisk.Up
+		}
+	}
+	start := s.gather(pos, ups)
+
+	if g.Goal.Kind == GoalLayout {
+		return s.planLayout(pos, ups, start, layoutPositions(g.Goal.Layout))
+	}
+
+	n := len(pos)
+	best, to, up := magnetUnreachable, 0, NoPole
+	for _, t := range g.Goal.targets(len(g.Pegs)) {
+		for _, u := range magnetPoles {
+			if c := gatherMoves(start, n, t, u); c < best {
+				best, to, up = c, t, u
+			}
+		}
+	}
+	if best == magnetUnreachable {
+		return 0, nil, ErrNoSolution
+	}
+
+	return best, func() []Move {
+		s.moves = make([]Move, 0, best)
+		s.emitGather(start, n, to, up)
+		return s.moves
+	}, nil
+}
+
+var magnetPoles = [...]Pole{North, South}
+
+// magnetUnreachable is the distance to positions which cannot be reached,
+// counts of moves saturate just below it.
+const magnetUnreachable uint64 = math.MaxUint64
+
+func addMoves(a uint64, b uint64) uint64 {
+	if a == magnetUnreachable || b == magnetUnreachable {
+		return magnetUnreachable
+	}
+	return min(satAdd(a, b), magnetUnreachable-1)
+}
+
+type magnetSolver struct {
+	pegs int
+	// towers are searches moving a tower of given height from peg 0 to
+	// any other of three pegs
+	towers map[towerKey]*magnetSearch
+	moves  []Move
+}
+
+type towerKey struct {
+	disks int
+	up    Pole
+	bases [3]Pole
+}
+
+// magnetSearch holds shortest paths over places of disk k and the tower of
+// smaller disks. Bigger disks do not move.
+type magnetSearch struct {
+	pegs  int
+	disks int
+	// bases is the pole shown by bigger disks of every peg, NoPole when
+	// there are none
+	bases []Pole
+	dist  []uint64
+	prev  []int
+}
+
+// magnetNode is a position of a search: disk k lies on peg disk with pole
+// up and smaller ones lie on peg tower with pole towerUp. The tower lies on
+// disk k when pegs are the same.
+type magnetNode struct {
+	disk    int
+	up      Pole
+	tower   int
+	towerUp Pole
+}
+
+func (m *magnetSearch) index(n magnetNode) int {
+	return ((n.disk*2+int(n.up)-1)*m.pegs+n.tower)*2 + int(n.towerUp) - 1
+}
+
+func (m *magnetSearch) node(i int) magnetNode {
+	return magnetNode{
+		disk:    i / 2 / m.pegs / 2,
+		up:      Pole(i/2/m.pegs%2 + 1),
+		tower:   i / 2 % m.pegs,
+		towerUp: Pole(i%2 + 1),
+	}
+}
+
+// valid tells whether disks may lie so, all disks of a peg show one pole.
+func (m *magnetSearch) valid(n magnetNode) bool {
+	if b := m.bases[n.disk]; b != NoPole && b != n.up {
+		return false
+	}
+	if n.tower == n.disk {
+		return n.towerUp == n.up
+	}
+	b := m.bases[n.tower]
+	return b == NoPole || b == n.towerUp
+}
+
+// gather returns searches which stack disks 1..k from given position into
+// a tower, indexed by k. ups is the pole shown on every peg.
+func (s *magnetSolver) gather(pos []int, ups []Pole) []*magnetSearch {
+	levels := make([]*magnetSearch, len(pos)+1)
+	bases := make([][]Pole, len(pos)+1)
+	bases[len(pos)] = make([]Pole, s.pegs)
+	for k := len(pos); k > 1; k-- {
+		bases[k-1] = slices.Clone(bases[k])
+		bases[k-1][pos[k-1]] = ups[pos[k-1]]
+	}
+
+	for k := 1; k <= len(pos); k++ {
+		p := pos[k-1]
+		levels[k] = &magnetSearch{pegs: s.pegs, disks: k, bases: bases[k]}
+		s.run(levels[k], func(n magnetNode) uint64 {
+			if n.disk != p || n.up != ups[p] {
+				return magnetUnreachable
+			}
+			return gatherMoves(levels, k-1, n.tower, n.towerUp)
+		})
+	}
+	return levels
+}
+
+// gatherMoves is how many moves stack disks 1..k on peg to with pole up.
+func gatherMoves(levels []*magnetSearch, k int, to int, up Pole) uint64 {
+	if k == 0 {
+		return 0
+	}
+	m := levels[k]
+	return m.dist[m.index(magnetNode{to, up, to, up})]
+}
+
+// planLayout is planMagnetic for a layout goal. Disks are gathered into
+// a tower and the tower is scattered to the goal, which is gathering played
+// backwards. Bigger disks which are in place may stay.
+//
+// Poles shown in the goal are free. Every choice is tried when the goal
+// takes up to four pegs, otherwise pegs keep the poles shown now and empty
+// ones get dealt poles.
+func (s *magnetSolver) planLayout(pos []int, ups []Pole, start []*magnetSearch, goal []int) (uint64, func() []Move, error) {
+	goalUps := make([]Pole, s.pegs)
+	for _, p := range goal {
+		goalUps[p] = MagneticRuleset{}.pole(p)
+		if ups[p] != NoPole {
+			goalUps[p] = ups[p]
+		}
+	}
+
+	var occupied []int
+	for p, up := range goalUps {
+		if up != NoPole {
+			occupied = append(occupied, p)
+		}
+	}
+	tries := [][]Pole{goalUps}
+	if len(occupied) <= 4 {
+		tries = nil
+		for mask := range 1 << len(occupied) {
+			t := slices.Clone(goalUps)
+			for i, p := range occupied {
+				t[p] = magnetPoles[mask>>i&1]
+			}
+			tries = append(tries, t)
+		}
+	}
+
+	best, meet := magnetUnreachable, layoutMeet{}
+	for _, t := range tries {
+		if c, m := s.meetLayout(pos, ups, start, goal, t); c < best {
+			best, meet = c, m
+		}
+	}
+
+	if best == magnetUnreachable {
+		return 0, nil, ErrNoSolution
+	}
+
+	return best, func() []Move {
+		s.moves = make([]Move, 0, best)
+		m := start[meet.disks]
+		s.emit(m, meet.end, func(n magnetNode) {
+			s.emitGather(start, meet.disks-1, n.tower, n.towerUp)
+		})
+
+		r := &magnetSolver{pegs: s.pegs, towers: s.towers}
+		n := m.node(meet.end)
+		r.emitGather(meet.goal, meet.disks-1, n.tower, n.towerUp)
+		for i := len(r.moves) - 1; i >= 0; i-- {
+			s.moves = append(s.moves, Move{From: r.moves[i].To, To: r.moves[i].From})
+		}
+		return s.moves
+	}, nil
+}
+
+// layoutMeet is where the gathering from the start meets the one from the
+// goal: disk k is in place and smaller disks are in a tower.
+type layoutMeet struct {
+	disks int
+	// end is the position in the start search of disk k
+	end int
+	// goal gathers disks from the goal
+	goal []*magnetSearch
+}
+
+// meetLayout finds the shortest way to the goal showing given poles. Disk k
+// goes to its place while smaller disks meet in a tower, bigger disks stay.
+func (s *magnetSolver) meetLayout(pos []int, ups []Pole, start []*magnetSearch, goal []int, goalUps []Pole) (uint64, layoutMeet) {
+	in := len(pos)
+	for in > 0 && pos[in-1] == goal[in-1] && ups[pos[in-1]] == goalUps[goal[in-1]] {
+		in--
+	}
+
+	levels := s.gather(goal, goalUps)
+	best, meet := magnetUnreachable, layoutMeet{}
+	for k := max(in, 1); k <= len(pos); k++ {
+		m := start[k]
+		for w := range s.pegs {
+			for _, up := range magnetPoles {
+				i := m.index(magnetNode{goal[k-1], goalUps[goal[k-1]], w, up})
+				if c := addMoves(m.dist[i], gatherMoves(levels, k-1, w, up)); c < best {
+					best, meet = c, layoutMeet{k, i, levels}
+				}
+			}
+		}
+	}
+	return best, meet
+}
+
+// run finds shortest paths from all positions given by source, which
+// returns how many moves it takes to get there.
+func (s *magnetSolver) run(m *magnetSearch, source func(magnetNode) uint64) {
+	size := 4 * m.pegs * m.pegs
+	m.dist = make([]uint64, size)
+	m.prev = make([]int, size)
+
+	q := &magnetQueue{}
+	for i := range size {
+		m.dist[i], m.prev[i] = magnetUnreachable, -1
+		if n := m.node(i); m.valid(n) {
+			m.dist[i] = source(n)
+			if m.dist[i] != magnetUnreachable {
+				heap.Push(q, magnetItem{i, m.dist[i]})
+			}
+		}
+	}
+
+	for q.Len() > 0 {
+		it := heap.Pop(q).(magnetItem)
+		if it.dist > m.dist[it.index] {
+			continue
+		}
+		s.next(m, m.node(it.index), func(next magnetNode, moves uint64) {
+			j := m.index(next)
+			if d := addMoves(it.dist, moves); d < m.dist[j] {
+				m.dist[j], m.prev[j] = d, it.index
+				heap.Push(q, magnetItem{j, d})
+			}
+		})
+	}
+}
+
+// next visits positions one step away: disk k moves while smaller disks
+// are elsewhere, or the tower of them moves.
+func (s *magnetSolver) next(m *magnetSearch, n magnetNode, visit func(magnetNode, uint64)) {
+	if n.tower != n.disk {
+		for p := range m.pegs {
+			if p != n.disk && p != n.tower && m.bases[p] != n.up {
+				visit(magnetNode{p, n.up.Flip(), n.tower, n.towerUp}, 1)
+			}
+		}
+	}
+
+	for p := range m.pegs {
+		if p == n.tower {
+			continue
+		}
+		for _, up := range magnetPoles {
+			if next := (magnetNode{n.disk, n.up, p, up}); m.valid(next) {
+				_, _, moves := s.towerMove(m, n, next)
+				visit(next, moves)
+			}
+		}
+	}
+}
+
+// towerMove is the cheapest way to move the tower of smaller disks between
+// given positions. Returns the spare peg and poles of the three pegs.
+func (s *magnetSolver) towerMove(m *magnetSearch, n magnetNode, next magnetNode) (int, [3]Pole, uint64) {
+	base := func(p int) Pole {
+		if p == n.disk {
+			return n.up
+		}
+		return m.bases[p]
+	}
+
+	spare, bases, best := -1, [3]Pole{}, magnetUnreachable
+	var tried [3]bool
+	for p := range m.pegs {
+		if p == n.tower || p == next.tower || tried[base(p)] {
+			continue
+		}
+		tried[base(p)] = true
+
+		b := [3]Pole{base(n.tower), base(next.tower), base(p)}
+		if moves := s.tower(m.disks-1, n.towerUp, next.towerUp, b); spare == -1 || moves < best {
+			spare, bases, best = p, b, moves
+		}
+	}
+	return spare, bases, best
+}
+
+// tower is how many moves take disks 1..k from peg 0 with pole up to peg 1
+// with pole upTo, when pegs 0, 1 and 2 show given poles below them.
+func (s *magnetSolver) tower(disks int, up Pole, upTo Pole, bases [3]Pole) uint64 {
+	if disks == 0 {
+		return 0
+	}
+	m := s.towerSearch(disks, up, bases)
+	return m.dist[m.index(magnetNode{1, upTo, 1, upTo})]
+}
+
+func (s *magnetSolver) towerSearch(disks int, up Pole, bases [3]Pole) *magnetSearch {
+	key := towerKey{disks, up, bases}
+	if m, ok := s.towers[key]; ok {
+		return m
+	}
+
+	m := &magnetSearch{pegs: 3, disks: disks, bases: bases[:]}
+	start := magnetNode{0, up, 0, up}
+	s.run(m, func(n magnetNode) uint64 {
+		if n != start {
+			return magnetUnreachable
+		}
+		return 0
+	})
+	s.towers[key] = m
+	return m
+}
+
+// emitGather makes moves which stack disks 1..k on peg to with pole up.
+func (s *magnetSolver) emitGather(levels []*magnetSearch, k int, to int, up Pole) {
+	if k == 0 {
+		return
+	}
+	m := levels[k]
+	s.emit(m, m.index(magnetNode{to, up, to, up}), func(n magnetNode) {
+		s.emitGather(levels, k-1, n.tower, n.towerUp)
+	})
+}
+
+// emitTower makes moves of tower (see tower) between pegs of the game.
+func (s *magnetSolver) emitTower(disks int, up Pole, upTo Pole, bases [3]Pole, pegs [3]int) {
+	if disks == 0 {
+		return
+	}
+	m := s.towerSearch(disks, up, bases)
+	s.emitOn(m, m.index(magnetNode{1, upTo, 1, upTo}), pegs[:], func(magnetNode) {})
+}
+
+// emit makes moves along the path of a search over all pegs to position
+// end, source makes the moves leading to where the path starts.
+func (s *magnetSolver) emit(m *magnetSearch, end int, source func(magnetNode)) {
+	pegs := make([]int, s.pegs)
+	for i := range pegs {
+		pegs[i] = i
+	}
+	s.emitOn(m, end, pegs, source)
+}
+
+// emitOn is emit where pegs of the search are given pegs of the game.
+func (s *magnetSolver) emitOn(m *magnetSearch, end int, pegs []int, source func(magnetNode)) {
+	var path []int
+	for i := end; i != -1; i = m.prev[i] {
+		path = append(path, i)
+	}
+	slices.Reverse(path)
+
+	source(m.node(path[0]))
+	for i := 1; i < len(path); i++ {
+		n, next := m.node(path[i-1]), m.node(path[i])
+		if n.disk != next.disk {
+			s.moves = append(s.moves, Move{From: pegs[n.disk], To: pegs[next.disk]})
+			continue
+		}
+		spare, bases, _ := s.towerMove(m, n, next)
+		s.emitTower(m.disks-1, n.towerUp, next.towerUp, bases, [3]int{pegs[n.tower], pegs[next.tower], pegs[spare]})
+	}
+}
+
+type magnetItem struct {
+	index int
+	dist  uint64
+}
+
+// magnetQueue is a priority queue of search positions, closest first.
+type magnetQueue []magnetItem
+
+func (q magnetQueue) Len() int           { return len(q) }
+func (q magnetQueue) Less(i, j int) bool { return q[i].dist < q[j].dist }
+func (q magnetQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *magnetQueue) Push(x any)        { *q = append(*q, x.(magnetItem)) }
+
+func (q *magnetQueue) Pop() any {
+	old := *q
+	it := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return it
+}
@@ -0,0 +1,230 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// poles lists which pole faces up on disks of every peg from top to bottom.
+func poles(g *Game) [][]Pole {
+	l := make([][]Pole, len(g.Pegs))
+	for i, p := range g.Pegs {
+		for d := p.TopDisk; d != nil; d = d.Next {
+			l[i] = append(l[i], d.Up)
+		}
+	}
+	return l
+}
+
+func TestMagneticMoves(t *testing.T) {
+	g, err := NewGame(3, 3, &Player{}, DefaultColorPicker(), WithStartLayout(ClassicStart), WithRuleset(MagneticRuleset{}))
+	require.NoError(t, err)
+	assert.Equal(t, [][]Pole{{North, North, North}, nil, nil}, poles(g))
+
+	require.NoError(t, g.MoveDisk(0, 1))
+	require.NoError(t, g.MoveDisk(0, 2))
+	assert.Equal(t, [][]Pole{{North}, {South}, {South}}, poles(g), "disks turn over")
+
+	assert.ErrorIs(t, g.MoveDisk(1, 2), ErrLikePoles)
+	assert.ErrorIs(t, g.MoveDisk(2, 1), ErrBiggerOnSmaller)
+	assert.ErrorIs(t, g.MoveDisk(1, 1), ErrIllegalMove)
+	assert.Equal(t, [][]Pole{{North}, {South}, {South}}, poles(g), "lone disk is not turned over in place")
+	assert.Equal(t, uint(2), g.Step)
+	require.NoError(t, g.MoveDisk(1, 0))
+	assert.Equal(t, [][]Pole{{North, North}, nil, {South}}, poles(g))
+
+	_, err = g.Undo()
+	require.NoError(t, err)
+	assert.Equal(t, [][]Pole{{North}, {South}, {South}}, poles(g), "undo turns disk back")
+	_, err = g.Redo()
+	require.NoError(t, err)
+	assert.Equal(t, [][]Pole{{North, North}, nil, {South}}, poles(g))
+}
+
+func TestPutDiskPoles(t *testing.T) {
+	var p Peg
+	require.NoError(t, p.PutDisk(&Disk{ID: 2, Size: 2, Up: South}))
+	assert.ErrorIs(t, p.PutDisk(&Disk{ID: 1, Size: 1, Up: North}), ErrLikePoles)
+	require.NoError(t, p.PutDisk(&Disk{ID: 1, Size: 1, Up: South}))
+	require.NoError(t, p.PutDisk(&Disk{ID: 0, Size: 0}), "disks without poles do not repel")
+}
+
+func TestMagneticDealtPoles(t *testing.T) {
+	l := Layout{{3}, {2}, {}, {1}}
+	g, err := NewGame(4, 3, &Player{}, DefaultColorPicker(), WithStartLayout(CustomStart(l)), WithRuleset(MagneticRuleset{}))
+	require.NoError(t, err)
+	assert.Equal(t, [][]Pole{{North}, {South}, nil, {South}}, poles(g))
+
+	g, err = NewGame(4, 3, &Player{}, DefaultColorPicker(), WithStartLayout(CustomStart(l)))
+	require.NoError(t, err)
+	assert.Equal(t, [][]Pole{{NoPole}, {NoPole}, nil, {NoPole}}, poles(g), "standard disks have no poles")
+}
+
+// magneticShortestPath finds length of the shortest solution by brute force,
+// turning over every single disk as it moves.
+func magneticShortestPath(t *testing.T, g *Game) int {
+	t.Helper()
+
+	type position struct {
+		pos []int
+		up  []Pole
+	}
+	start := position{pos: make([]int, g.TotalDisks), up: make([]Pole, g.TotalDisks)}
+	for i, p := range g.Pegs {
+		for d := p.TopDisk; d != nil; d = d.Next {
+			start.pos[d.ID-1], start.up[d.ID-1] = i, d.Up
+		}
+	}
+
+	key := func(p position) string { return fmt.Sprint(p.pos, p.up) }
+	seen := map[string]struct{}{key(start): {}}
+	layer := []position{start}
+	for depth := 0; len(layer) > 0; depth++ {
+		var next []position
+		for _, p := range layer {
+			if g.Goal.reached(p.pos) {
+				return depth
+			}
+
+			top := make([]int, len(g.Pegs))
+			for i := range top {
+				top[i] = -1
+			}
+			for d := len(p.pos) - 1; d >= 0; d-- {
+				top[p.pos[d]] = d
+			}
+			for from, d := range top {
+				for to, under := range top {
+					if d == -1 || from == to || (under != -1 && (under < d || p.up[under] == p.up[d])) {
+						continue
+					}
+					n := position{pos: slices.Clone(p.pos), up: slices.Clone(p.up)}
+					n.pos[d], n.up[d] = to, p.up[d].Flip()
+					if _, ok := seen[key(n)]; !ok {
+						seen[key(n)] = struct{}{}
+						next = append(next, n)
+					}
+				}
+			}
+		}
+		layer = next
+	}
+
+	t.Fatal("no solution found")
+	return 0
+}
+
+func TestSolveMagneticTower(t *testing.T) {
+	for disks := uint(1); disks <= 7; disks++ {
+		t.Run(fmt.Sprintf("%d disks", disks), func(t *testing.T) {
+			g, err := NewGame(3, disks, &Player{}, DefaultColorPicker(),
+				WithStartLayout(ClassicStart), WithGoal(PegGoal(2)), WithRuleset(MagneticRuleset{}))
+			require.NoError(t, err)
+
+			moves, err := Solve(g)
+			require.NoError(t, err)
+			assert.Len(t, moves, magneticShortestPath(t, g))
+
+			applyMoves(t, g, moves)
+			assert.True(t, g.IsWon())
+		})
+	}
+}
+
+func TestSolveMagneticIsShortest(t *testing.T) {
+	goals := []Goal{AnyPegGoal(), PegGoal(1), LayoutGoal(Layout{{4, 1}, {}, {5, 3, 2}})}
+	for disks := uint(1); disks <= 5; disks++ {
+		for i, goal := range goals {
+			if goal.validate(3, int(disks), MagneticRuleset{}) != nil {
+				continue
+			}
+			for seed := range int64(10) {
+				t.Run(fmt.Sprintf("%d disks goal %d seed %d", disks, i, seed), func(t *testing.T) {
+					g, err := NewGame(3, disks, &Player{}, DefaultColorPicker(),
+						WithSeed(seed), WithGoal(goal), WithRuleset(MagneticRuleset{}))
+					require.NoError(t, err)
+
+					moves, err := Solve(g)
+					require.NoError(t, err)
+					assert.Len(t, moves, magneticShortestPath(t, g))
+
+					applyMoves(t, g, moves)
+					assert.True(t, g.IsWon())
+				})
+			}
+		}
+	}
+}
+
+func TestSolveMagneticBigBoards(t *testing.T) {
+	g, err := NewGame(3, 14, &Player{}, DefaultColorPicker(),
+		WithStartLayout(ClassicStart), WithGoal(PegGoal(2)), WithRuleset(MagneticRuleset{}))
+	require.NoError(t, err)
+	_, left, err := NextBestMove(g)
+	require.NoError(t, err)
+	par, err := Par(g)
+	require.NoError(t, err)
+	assert.Equal(t, par, left)
+
+	g, err = NewGame(3, 30, &Player{}, DefaultColorPicker(), WithRuleset(MagneticRuleset{}))
+	require.NoError(t, err)
+	_, err = Solve(g)
+	assert.ErrorIs(t, err, ErrSolutionTooLong)
+	par, err = Par(g)
+	require.NoError(t, err, "par is counted without making moves")
+	assert.Greater(t, par, maxSolutionMoves)
+
+	g, err = NewGame(3, 64, &Player{}, DefaultColorPicker(), WithRuleset(MagneticRuleset{}))
+	require.NoError(t, err)
+	_, err = Par(g)
+	assert.ErrorIs(t, err, ErrSolutionTooLong)
+
+	g, err = NewGame(4, 9, &Player{}, DefaultColorPicker(), WithSeed(1), WithRuleset(MagneticRuleset{}))
+	require.NoError(t, err)
+	moves, err := Solve(g)
+	require.NoError(t, err)
+	applyMoves(t, g, moves)
+	assert.True(t, g.IsWon())
+}
+
+func TestMagneticDistanceStart(t *testing.T) {
+	for n := range 12 {
+		g, err := NewGame(3, 4, &Player{}, DefaultColorPicker(), WithSeed(int64(n)),
+			WithStartLayout(DistanceStart(n)), WithGoal(PegGoal(2)), WithRuleset(MagneticRuleset{}))
+		require.NoError(t, err)
+
+		moves, err := SolveExact(g, 0)
+		require.NoError(t, err)
+		assert.Len(t, moves, n)
+	}
+}
+
+func TestMagneticReplay(t *testing.T) {
+	rules, err := ParseRuleset(MagneticRulesetName)
+	require.NoError(t, err)
+	g, err := NewGame(3, 4, &Player{}, DefaultColorPicker(), WithSeed(3), WithRuleset(rules))
+	require.NoError(t, err)
+
+	moves, err := Solve(g)
+	require.NoError(t, err)
+	par, err := Par(g)
+	require.NoError(t, err)
+	assert.Equal(t, len(moves), par)
+
+	applyMoves(t, g, moves)
+	_, err = g.Undo()
+	require.NoError(t, err)
+	_, err = g.Redo()
+	require.NoError(t, err)
+
+	replayed, err := Replay(g.Recording(), &Player{}, DefaultColorPicker())
+	require.NoError(t, err)
+	assert.Equal(t, g.Layout(), replayed.Layout())
+	assert.Equal(t, poles(g), poles(replayed))
+	assert.Equal(t, MagneticRuleset{}, replayed.Rules)
+	assert.True(t, replayed.IsWon())
+}
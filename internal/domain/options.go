@@ -0,0 +1,58 @@
+package domain
+
+import "math/rand"
+
+// GameOption tunes a game created by NewGame.
+type GameOption func(*gameConfig)
+
+type gameConfig struct {
+	seed   int64
+	seeded bool
+	start  StartLayout
+	goal   Goal
+	rules  Ruleset
+}
+
+func newGameConfig(opts []GameOption) gameConfig {
+	cfg := gameConfig{start: RandomStart, rules: StandardRuleset{}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if !cfg.seeded {
+		cfg.seed = rand.Int63()
+	}
+
+	return cfg
+}
+
+// WithSeed makes the start layout reproducible: games with the same seed,
+// pegs and disks count look the same. Without it a random seed is used.
+func WithSeed(seed int64) GameOption {
+	return func(cfg *gameConfig) {
+		cfg.seed = seed
+		cfg.seeded = true
+	}
+}
+
+// WithStartLayout sets how disks are placed at the start, RandomStart is used
+// by default.
+func WithStartLayout(start StartLayout) GameOption {
+	return func(cfg *gameConfig) {
+		cfg.start = start
+	}
+}
+
+// WithGoal sets which positions are won, GoalAnyPeg is used by default.
+func WithGoal(goal Goal) GameOption {
+	return func(cfg *gameConfig) {
+		cfg.goal = goal
+	}
+}
+
+// WithRuleset sets the rules of the game, StandardRuleset is used by default.
+func WithRuleset(rules Ruleset) GameOption {
+	return func(cfg *gameConfig) {
+		cfg.rules = rules
+	}
+}
@@ -1,6 +1,11 @@
 package domain
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrLikePoles = errors.New("like poles of disks cannot touch")
 
 // Peg is a stick which holds disks in Tower of Hanoi
 type Peg struct {
@@ -25,6 +30,11 @@ func (p *Peg) PutDisk(disk *Disk) error {
 		return fmt.Errorf("PutDisk: disk cannot be nil")
 	}
 
+	// The disk faces the top one with the pole opposite to its upper one.
+	if top := p.TopDisk; top != nil && disk.Up != NoPole && top.Up != NoPole && disk.Up != top.Up {
+		return fmt.Errorf("PutDisk: %w", ErrLikePoles)
+	}
+
 	disk.Next = p.TopDisk
 	p.TopDisk = disk
 	p.totalDisks++
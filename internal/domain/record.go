@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrGameNotWon = errors.New("game is not won yet")
+
+type RecordID int
+
+// Record is a won game in the leaderboard.
+type Record struct {
+	ID       RecordID
+	PlayerID PlayerID
+	Nickname string
+	Steps    uint
+	Pegs     int
+	Disks    int
+	// Goal is in the format of Goal.String, see recordGoal
+	Goal       string
+	Ruleset    string
+	HintsUsed  uint
+	AchievedAt time.Time
+}
+
+// RecordFilter narrows leaderboard down, zero fields match anything.
+type RecordFilter struct {
+	Pegs    int
+	Disks   int
+	Ruleset string
+	// Goal is in the format of Goal.String
+	Goal  string
+	Limit int
+}
+
+// RecordRepository stores records. GetTop returns the best ones first:
+// fewer steps, then fewer hints, then the earlier one.
+type RecordRepository interface {
+	Save(ctx context.Context, r *Record) (RecordID, error)
+	GetTop(ctx context.Context, filter RecordFilter) ([]*Record, error)
+}
+
+// NewRecord makes a leaderboard record of the won game.
+func NewRecord(g *Game) (*Record, error) {
+	if g.Player == nil {
+		return nil, ErrPlayerCannotBeNil
+	}
+
+	if !g.IsWon() {
+		return nil, ErrGameNotWon
+	}
+
+	r := &Record{
+		PlayerID:   g.Player.ID,
+		Nickname:   g.Player.Nickname,
+		Steps:      g.Step,
+		Pegs:       len(g.Pegs),
+		Disks:      g.TotalDisks,
+		Goal:       recordGoal(g),
+		Ruleset:    g.rules().Name(),
+		HintsUsed:  g.HintsUsed,
+		AchievedAt: time.Now(),
+	}
+
+	return r, nil
+}
+
+// recordGoal is the goal the game is ranked by. Rules may turn the any peg
+// goal into another one, bicolor makes it a layout, such games still rank
+// under any peg, so their records are found without spelling the layout out.
+func recordGoal(g *Game) string {
+	goal, err := checkBoard(g.rules(), len(g.Pegs), g.TotalDisks, AnyPegGoal())
+	if err == nil && goal.String() == g.Goal.String() {
+		return AnyPegGoal().String()
+	}
+	return g.Goal.String()
+}
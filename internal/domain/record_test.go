@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRecord(t *testing.T) {
+	player := &Player{ID: 7, Nickname: "anru"}
+	g, err := NewGame(3, 3, player, DefaultColorPicker(), WithStartLayout(ClassicStart), WithGoal(PegGoal(2)))
+	require.NoError(t, err)
+
+	_, err = NewRecord(g)
+	assert.ErrorIs(t, err, ErrGameNotWon)
+
+	_, _, err = g.Hint()
+	require.NoError(t, err)
+
+	moves, err := Solve(g)
+	require.NoError(t, err)
+	applyMoves(t, g, moves)
+
+	r, err := NewRecord(g)
+	require.NoError(t, err)
+	assert.Equal(t, player.ID, r.PlayerID)
+	assert.Equal(t, player.Nickname, r.Nickname)
+	assert.Equal(t, uint(7), r.Steps)
+	assert.Equal(t, 3, r.Pegs)
+	assert.Equal(t, 3, r.Disks)
+	assert.Equal(t, "peg:2", r.Goal)
+	assert.Equal(t, uint(1), r.HintsUsed)
+	assert.False(t, r.AchievedAt.IsZero())
+}
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"time"
+)
+
+var ErrReplayMismatch = errors.New("replay does not match the log")
+
+type MoveKind string
+
+const (
+	KindMove MoveKind = "move"
+	KindUndo MoveKind = "undo"
+	KindRedo MoveKind = "redo"
+)
+
+// LoggedMove is a single disk transfer made in the game. For undo From and To
+// are the pegs the disk has actually moved between.
+type LoggedMove struct {
+	Kind     MoveKind  `json:"kind"`
+	From     int       `json:"from"`
+	To       int       `json:"to"`
+	DiskSize uint      `json:"disk_size"`
+	At       time.Time `json:"at"`
+}
+
+// Recording is everything needed to rebuild a game: where disks were at
+// start and what was done with them.
+type Recording struct {
+	Seed   int64        `json:"seed"`
+	Goal   string       `json:"goal"`
+	Layout Layout       `json:"layout"`
+	Moves  []LoggedMove `json:"moves"`
+	// Ruleset is the name of the rules, empty for recordings made before
+	// there were any other than standard
+	Ruleset string `json:"ruleset,omitempty"`
+	// HintsUsed is kept so restored games are not taken for unassisted ones
+	HintsUsed uint `json:"hints_used,omitempty"`
+}
+
+// Log returns all moves made in the game in order.
+func (g *Game) Log() []LoggedMove {
+	return append([]LoggedMove{}, g.log...)
+}
+
+// Recording returns initial layout and log of the game.
+func (g *Game) Recording() Recording {
+	return Recording{
+		Seed:      g.Seed,
+		Goal:      g.Goal.String(),
+		Layout:    g.InitialLayout(),
+		Moves:     g.Log(),
+		HintsUsed: g.HintsUsed,
+		Ruleset:   g.rules().Name(),
+	}
+}
+
+func (g *Game) logMove(kind MoveKind, fromPeg int, toPeg int, size uint) {
+	g.log = append(g.log, LoggedMove{
+		Kind:     kind,
+		From:     fromPeg,
+		To:       toPeg,
+		DiskSize: size,
+		At:       time.Now(),
+	})
+}
+
+// Replay rebuilds the game from its recording. Every move is checked to move
+// the same disk between the same pegs as it was logged, timestamps are kept.
+func Replay(rec Recording, player *Player, colorPicker func() color.Color) (*Game, error) {
+	return ReplayEach(rec, player, colorPicker, nil)
+}
+
+// ReplayEach is Replay which calls each after every restored move, so the
+// game can be shown step by step. The game must not be changed by each.
+func ReplayEach(rec Recording, player *Player, colorPicker func() color.Color, each func(g *Game, m LoggedMove)) (*Game, error) {
+	goal, err := ParseGoal(rec.Goal)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := ParseRuleset(rec.Ruleset)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := newGameFromLayout(rec.Layout, player, colorPicker, rules)
+	if err != nil {
+		return nil, fmt.Errorf("cannot restore initial layout: %w", err)
+	}
+
+	if err := goal.validate(len(g.Pegs), g.TotalDisks, rules); err != nil {
+		return nil, err
+	}
+	if goal, err = checkBoard(rules, len(g.Pegs), g.TotalDisks, goal); err != nil {
+		return nil, err
+	}
+	g.Seed = rec.Seed
+	g.Goal = goal
+	g.HintsUsed = rec.HintsUsed
+
+	for i, m := range rec.Moves {
+		switch m.Kind {
+		case KindMove:
+			err = g.MoveDisk(m.From, m.To)
+		case KindUndo:
+			_, err = g.Undo()
+		case KindRedo:
+			_, err = g.Redo()
+		default:
+			err = fmt.Errorf("unknown move kind %q", m.Kind)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: move #%d: %w", ErrReplayMismatch, i, err)
+		}
+
+		got := &g.log[len(g.log)-1]
+		if got.From != m.From || got.To != m.To || got.DiskSize != m.DiskSize {
+			return nil, fmt.Errorf("%w: move #%d: disk %d from %d to %d, logged disk %d from %d to %d",
+				ErrReplayMismatch, i, got.DiskSize, got.From, got.To, m.DiskSize, m.From, m.To)
+		}
+		got.At = m.At
+
+		if each != nil {
+			each(g, *got)
+		}
+	}
+
+	return g, nil
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay(t *testing.T) {
+	start := Layout{{6, 1}, {5, 4, 3}, {2}}
+	g, err := newGameFromLayout(start, &Player{ID: 1}, DefaultColorPicker(), StandardRuleset{})
+	require.NoError(t, err)
+
+	moves, err := Solve(g)
+	require.NoError(t, err)
+	applyMoves(t, g, moves)
+	_, err = g.Undo()
+	require.NoError(t, err)
+	_, err = g.Undo()
+	require.NoError(t, err)
+	_, err = g.Redo()
+	require.NoError(t, err)
+
+	assert.Equal(t, start, g.InitialLayout())
+
+	data, err := json.Marshal(g.Recording())
+	require.NoError(t, err)
+	var rec Recording
+	require.NoError(t, json.Unmarshal(data, &rec))
+
+	got, err := Replay(rec, g.Player, DefaultColorPicker())
+	require.NoError(t, err)
+
+	assert.Equal(t, g.Layout(), got.Layout())
+	assert.Equal(t, g.Step, got.Step)
+	assert.Equal(t, g.NetSteps(), got.NetSteps())
+	assert.Equal(t, g.IsWon(), got.IsWon())
+	require.Len(t, got.Log(), len(g.Log()))
+	for i, m := range g.Log() {
+		assert.True(t, m.At.Equal(got.Log()[i].At))
+		assert.Equal(t, m.DiskSize, got.Log()[i].DiskSize)
+	}
+
+	for i, p := range g.Pegs {
+		for d, r := p.TopDisk, got.Pegs[i].TopDisk; d != nil; d, r = d.Next, r.Next {
+			assert.Equal(t, d.Color, r.Color, "colors are picked in the same order")
+		}
+	}
+}
+
+func TestReplayMismatch(t *testing.T) {
+	g, err := newGameFromLayout(Layout{{3, 2, 1}, {}, {}}, &Player{}, DefaultColorPicker(), StandardRuleset{})
+	require.NoError(t, err)
+	require.NoError(t, g.MoveDisk(0, 1))
+	require.NoError(t, g.MoveDisk(0, 2))
+
+	rec := g.Recording()
+	rec.Moves[1].DiskSize = 3
+	_, err = Replay(rec, g.Player, DefaultColorPicker())
+	assert.True(t, errors.Is(err, ErrReplayMismatch))
+
+	rec = g.Recording()
+	rec.Moves[1].To = 1
+	_, err = Replay(rec, g.Player, DefaultColorPicker())
+	assert.True(t, errors.Is(err, ErrReplayMismatch))
+
+	rec = g.Recording()
+	rec.Moves = append(rec.Moves, LoggedMove{Kind: KindRedo})
+	_, err = Replay(rec, g.Player, DefaultColorPicker())
+	assert.True(t, errors.Is(err, ErrReplayMismatch))
+
+	_, err = Replay(Recording{Layout: Layout{{1, 2}, {}}}, g.Player, DefaultColorPicker())
+	assert.True(t, errors.Is(err, ErrInvalidLayout))
+}
+
+func TestReplayEach(t *testing.T) {
+	g, err := newGameFromLayout(Layout{{3, 2, 1}, {}, {}}, &Player{}, DefaultColorPicker(), StandardRuleset{})
+	require.NoError(t, err)
+	require.NoError(t, g.MoveDisk(0, 1))
+	require.NoError(t, g.MoveDisk(0, 2))
+	_, err = g.Undo()
+	require.NoError(t, err)
+
+	var steps []uint
+	var kinds []MoveKind
+	_, err = ReplayEach(g.Recording(), g.Player, DefaultColorPicker(), func(g *Game, m LoggedMove) {
+		steps = append(steps, g.Step)
+		kinds = append(kinds, m.Kind)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{1, 2, 3}, steps)
+	assert.Equal(t, []MoveKind{KindMove, KindMove, KindUndo}, kinds)
+}
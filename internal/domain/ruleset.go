@@ -0,0 +1,165 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrUnknownRuleset = errors.New("unknown ruleset")
+var ErrIllegalMove = errors.New("move is not allowed by the rules")
+
+// Ruleset decides which moves are legal and which positions are won, so
+// variants of the puzzle share the rest of the game.
+type Ruleset interface {
+	// Name identifies the ruleset in recordings, records and commands
+	Name() string
+	// CheckMove tells why the top disk of peg from cannot be put on peg
+	// to. Pegs are in range, different and from is not empty.
+	CheckMove(g *Game, from int, to int) error
+	// IsWon tells whether the game is won
+	IsWon(g *Game) bool
+	// Solve returns moves which bring the game to a won position, the game
+	// itself is not modified
+	Solve(g *Game) ([]Move, error)
+}
+
+const StandardRulesetName = "standard"
+
+// StandardRuleset is the classic puzzle: any top disk may be moved to any
+// peg unless it is put on a smaller disk.
+type StandardRuleset struct{}
+
+func (StandardRuleset) Name() string {
+	return StandardRulesetName
+}
+
+func (StandardRuleset) CheckMove(g *Game, from int, to int) error {
+	if top := g.Pegs[to].TopDisk; top != nil && g.Pegs[from].TopDisk.Size > top.Size {
+		return ErrBiggerOnSmaller
+	}
+	return nil
+}
+
+func (StandardRuleset) IsWon(g *Game) bool {
+	return g.goalReached()
+}
+
+func (StandardRuleset) Solve(g *Game) ([]Move, error) {
+	return solveStandard(g)
+}
+
+func (StandardRuleset) solvesShortest(g *Game) bool {
+	return len(g.Pegs) == 3
+}
+
+func (StandardRuleset) canMove(from int, to int, pegs int) bool {
+	return true
+}
+
+// Rulesets lists names of all known rulesets, the default one is first.
+func Rulesets() []string {
+	return []string{StandardRulesetName, CyclicRulesetName, AdjacentRulesetName, BicolorRulesetName, MagneticRulesetName}
+}
+
+// ParseRuleset returns ruleset by its name, empty name is the standard one.
+// Bicolor takes the number of colors as "bicolor:N".
+func ParseRuleset(name string) (Ruleset, error) {
+	if base, arg, ok := strings.Cut(name, ":"); ok && base == BicolorRulesetName {
+		colors, err := strconv.Atoi(arg)
+		if err != nil || colors < 2 || colors > len(towerColors) {
+			return nil, fmt.Errorf("%w %q: colors should be a number from 2 to %d, got %q", ErrUnknownRuleset, name, len(towerColors), arg)
+		}
+		return BicolorRuleset{Colors: colors}, nil
+	}
+
+	switch name {
+	case "", StandardRulesetName:
+		return StandardRuleset{}, nil
+	case CyclicRulesetName:
+		return CyclicRuleset{}, nil
+	case AdjacentRulesetName:
+		return AdjacentRuleset{}, nil
+	case BicolorRulesetName:
+		return BicolorRuleset{}, nil
+	case MagneticRulesetName:
+		return MagneticRuleset{}, nil
+	default:
+		return nil, fmt.Errorf("%w %q, want one of %v", ErrUnknownRuleset, name, Rulesets())
+	}
+}
+
+// diskSet is implemented by rulesets where disks differ by more than size,
+// layouts list disk IDs and the ruleset tells what every disk is.
+type diskSet interface {
+	disk(id uint, total uint) (size uint, c color.Color)
+}
+
+// boardChecker is implemented by rulesets which need a special board. It
+// returns the goal the game is played for.
+type boardChecker interface {
+	checkBoard(pegs int, disks int, goal Goal) (Goal, error)
+}
+
+// poleSet is implemented by rulesets where disks are magnets. It tells which
+// pole faces up on disks dealt to peg p, they turn over on every move then.
+type poleSet interface {
+	pole(p int) Pole
+}
+
+// diskSize is the size of disk with given ID.
+func diskSize(rules Ruleset, id uint, total uint) uint {
+	if set, ok := rules.(diskSet); ok {
+		size, _ := set.disk(id, total)
+		return size
+	}
+	return id
+}
+
+// checkBoard checks the board against the rules, see boardChecker.
+func checkBoard(rules Ruleset, pegs int, disks int, goal Goal) (Goal, error) {
+	if bc, ok := rules.(boardChecker); ok {
+		return bc.checkBoard(pegs, disks, goal)
+	}
+	return goal, nil
+}
+
+// rules returns rules of the game, a game built without them is standard.
+func (g *Game) rules() Ruleset {
+	if g.Rules == nil {
+		return StandardRuleset{}
+	}
+	return g.Rules
+}
+
+// goalReached checks the goal for disks stacked by size, it is the win
+// condition of most rulesets.
+func (g *Game) goalReached() bool {
+	switch g.Goal.Kind {
+	case GoalPeg:
+		return int(g.Pegs[g.Goal.Peg].totalDisks) == g.TotalDisks
+	case GoalLayout:
+		return slices.EqualFunc(g.Layout(), g.Goal.Layout, slices.Equal)
+	}
+
+	idx := -1
+	for i, p := range g.Pegs {
+		if p.totalDisks > 0 {
+			if idx != -1 {
+				return false
+			}
+			idx = i
+		}
+	}
+
+	for d := g.Pegs[idx].TopDisk; d.Next != nil; d = d.Next {
+		if d.Size > d.Next.Size {
+			return false
+		}
+	}
+
+	return true
+}
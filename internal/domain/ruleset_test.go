@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noReturnRuleset is standard, but disks cannot be put back on the first peg.
+type noReturnRuleset struct{ StandardRuleset }
+
+func (noReturnRuleset) Name() string {
+	return "no-return"
+}
+
+func (r noReturnRuleset) CheckMove(g *Game, from int, to int) error {
+	if to == 0 {
+		return ErrIllegalMove
+	}
+	return r.StandardRuleset.CheckMove(g, from, to)
+}
+
+func TestParseRuleset(t *testing.T) {
+	for _, name := range Rulesets() {
+		r, err := ParseRuleset(name)
+		require.NoError(t, err)
+		assert.Equal(t, name, r.Name())
+	}
+
+	r, err := ParseRuleset("")
+	require.NoError(t, err)
+	assert.Equal(t, StandardRuleset{}, r)
+
+	_, err = ParseRuleset("weird")
+	assert.ErrorIs(t, err, ErrUnknownRuleset)
+}
+
+func TestCustomRuleset(t *testing.T) {
+	g, err := NewGame(3, 3, &Player{}, DefaultColorPicker(), WithStartLayout(ClassicStart), WithRuleset(noReturnRuleset{}))
+	require.NoError(t, err)
+	assert.Equal(t, "no-return", g.Rules.Name())
+
+	require.NoError(t, g.MoveDisk(0, 2))
+	assert.ErrorIs(t, g.MoveDisk(2, 0), ErrIllegalMove)
+	assert.ErrorIs(t, g.MoveDisk(0, 2), ErrBiggerOnSmaller)
+	assert.Equal(t, uint(1), g.Step, "rejected moves are not counted")
+
+	rec := g.Recording()
+	assert.Equal(t, "no-return", rec.Ruleset)
+	_, err = Replay(rec, &Player{}, DefaultColorPicker())
+	assert.ErrorIs(t, err, ErrUnknownRuleset, "only known rulesets can be restored")
+}
+
+func TestStandardRulesetIsDefault(t *testing.T) {
+	g, err := NewGame(3, 3, &Player{}, DefaultColorPicker(), WithStartLayout(ClassicStart), WithGoal(PegGoal(2)))
+	require.NoError(t, err)
+	assert.Equal(t, StandardRuleset{}, g.Rules)
+
+	moves, err := Solve(g)
+	require.NoError(t, err)
+	applyMoves(t, g, moves)
+
+	rec, err := NewRecord(g)
+	require.NoError(t, err)
+	assert.Equal(t, StandardRulesetName, rec.Ruleset)
+
+	// recordings made before rulesets have none
+	recording := g.Recording()
+	recording.Ruleset = ""
+	replayed, err := Replay(recording, &Player{}, DefaultColorPicker())
+	require.NoError(t, err)
+	assert.Equal(t, StandardRuleset{}, replayed.Rules)
+	assert.True(t, replayed.IsWon())
+}
+
+func TestRulesetsSolveRandomStart(t *testing.T) {
+	goals := []Goal{AnyPegGoal(), PegGoal(1), LayoutGoal(Layout{{4, 1}, {}, {5, 3, 2}, {}})}
+	tests := []struct {
+		rules   Ruleset
+		maxPegs uint
+	}{
+		{CyclicRuleset{}, 4},
+		{AdjacentRuleset{}, 5},
+		{MagneticRuleset{}, 4},
+	}
+	for _, tt := range tests {
+		for pegs := uint(3); pegs <= tt.maxPegs; pegs++ {
+			for i, goal := range goals {
+				if goal.validate(int(pegs), 5, tt.rules) != nil {
+					continue
+				}
+				for seed := range int64(10) {
+					t.Run(fmt.Sprintf("%s %d pegs goal %d seed %d", tt.rules.Name(), pegs, i, seed), func(t *testing.T) {
+						g, err := NewGame(pegs, 5, &Player{}, DefaultColorPicker(),
+							WithSeed(seed), WithGoal(goal), WithRuleset(tt.rules))
+						require.NoError(t, err)
+
+						exact, err := SolveExact(g, 0)
+						require.NoError(t, err)
+						moves, err := Solve(g)
+						require.NoError(t, err)
+						if s, ok := tt.rules.(shortestSolver); ok && s.solvesShortest(g) {
+							assert.Len(t, moves, len(exact), "solution is the shortest")
+						} else {
+							assert.LessOrEqual(t, len(exact), len(moves))
+						}
+
+						applyMoves(t, g, moves)
+						assert.True(t, g.IsWon())
+					})
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,416 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrStateSpaceTooLarge = errors.New("state space is too large for exact search")
+var ErrNoSolution = errors.New("won position cannot be reached")
+var ErrUnsupportedRuleset = errors.New("exact search does not support the ruleset")
+
+// DefaultMaxStates is the cap on visited positions used by SolveExact when
+// none is given. Every position takes a few dozen bytes, so it is about
+// a couple hundred megabytes at most.
+const DefaultMaxStates = 1 << 22
+
+// SolveExact returns the shortest sequence of moves which brings the game to
+// a won position. Game itself is not modified.
+//
+// It runs bidirectional breadth-first search over all configurations, one
+// side starts from the current position and the other from every position
+// where the goal is reached.
+// Each configuration is a vector of peg indices, one per disk, packed into
+// a single number. maxStates limits how many configurations may be visited,
+// zero means DefaultMaxStates. ErrStateSpaceTooLarge is returned when the
+// limit is hit or the board cannot be packed at all.
+//
+// Only rulesets which restrict pegs a disk may move between, or make disks
+// magnets, are supported. ErrUnsupportedRuleset is returned for others.
+func SolveExact(g *Game, maxStates int) ([]Move, error) {
+	if maxStates <= 0 {
+		maxStates = DefaultMaxStates
+	}
+
+	if len(g.Pegs) < 2 {
+		return nil, ErrUnsupportedPegs
+	}
+
+	pos, err := diskPositions(g)
+	if err != nil {
+		return nil, err
+	}
+
+	sp, err := newRulesStateSpace(len(g.Pegs), len(pos), g.rules())
+	if err != nil {
+		return nil, err
+	}
+
+	start := sp.encode(pos)
+	if sp.magnets != nil {
+		for i, p := range g.Pegs {
+			if p.TopDisk != nil && p.TopDisk.Up == South {
+				start += sp.polePow << i
+			}
+		}
+	}
+
+	return sp.search(start, sp.goals(g.Goal), maxStates)
+}
+
+// boardFits tells whether every position of the board fits in maxStates,
+// so exact search cannot run out of them. Searching bigger boards may still
+// succeed but is likely to waste time up to the cap.
+func boardFits(g *Game, maxStates int) bool {
+	return positionsFit(len(g.Pegs), g.TotalDisks, g.rules(), maxStates)
+}
+
+// positionsFit is boardFits for a board which is not dealt yet.
+func positionsFit(pegs int, disks int, rules Ruleset, maxStates int) bool {
+	n := 1
+	if _, ok := rules.(poleSet); ok {
+		n <<= min(pegs, 62)
+	}
+	for range disks {
+		if n > maxStates {
+			return false
+		}
+		n *= pegs
+	}
+	return n <= maxStates
+}
+
+// searchesExactly tells whether Solve of a ruleset with no good strategy for
+// more than three pegs searches the board exactly instead of walking it.
+func searchesExactly(g *Game) bool {
+	return len(g.Pegs) > 3 && boardFits(g, hintMaxStates)
+}
+
+// pegGraph is implemented by rulesets which differ from the standard one
+// only in pegs a disk may move between, so their positions can be searched.
+type pegGraph interface {
+	canMove(from int, to int, pegs int) bool
+}
+
+// stateSpace packs disk positions into uint64, disk of size i+1 is the i-th
+// digit in base of pegs count.
+//
+// Magnetic disks on a peg all lie the same way up, so with magnets there is
+// one more bit per peg above the digits, it is set when disks of the peg
+// have south pole up. Bit of an empty peg is always zero.
+type stateSpace struct {
+	pegs  int
+	disks int
+	pow   []uint64
+	// moves tells whether a disk may go between pegs, nil allows any move
+	moves pegGraph
+	// magnets deal poles of disks, nil when disks have no poles
+	magnets poleSet
+	// polePow is the bit of peg 0 pole, every position of disks is below
+	polePow uint64
+}
+
+// newRulesStateSpace is newStateSpace with moves of the ruleset.
+func newRulesStateSpace(pegs int, disks int, rules Ruleset) (*stateSpace, error) {
+	graph, ok := rules.(pegGraph)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedRuleset, rules.Name())
+	}
+
+	sp, err := newStateSpace(pegs, disks)
+	if err != nil {
+		return nil, err
+	}
+	sp.moves = graph
+
+	if magnets, ok := rules.(poleSet); ok {
+		if sp.polePow > math.MaxUint64>>pegs {
+			return nil, fmt.Errorf("%w: %d pegs and %d disks", ErrStateSpaceTooLarge, pegs, disks)
+		}
+		sp.magnets = magnets
+	}
+	return sp, nil
+}
+
+func newStateSpace(pegs int, disks int) (*stateSpace, error) {
+	sp := &stateSpace{pegs: pegs, disks: disks, pow: make([]uint64, disks)}
+
+	var p uint64 = 1
+	for i := range disks {
+		sp.pow[i] = p
+		if p > math.MaxUint64/uint64(pegs) {
+			return nil, fmt.Errorf("%w: %d pegs and %d disks", ErrStateSpaceTooLarge, pegs, disks)
+		}
+		p *= uint64(pegs)
+	}
+	sp.polePow = p
+
+	return sp, nil
+}
+
+func (sp *stateSpace) encode(pos []int) uint64 {
+	var s uint64
+	for i, p := range pos {
+		s += uint64(p) * sp.pow[i]
+	}
+	return s
+}
+
+func (sp *stateSpace) peg(s uint64, disk int) int {
+	return int(s / sp.pow[disk] % uint64(sp.pegs))
+}
+
+// goals returns all positions where the goal is reached.
+func (sp *stateSpace) goals(goal Goal) []uint64 {
+	var states []uint64
+	switch goal.Kind {
+	case GoalPeg:
+		states = []uint64{sp.gathered()[goal.Peg]}
+	case GoalLayout:
+		states = []uint64{sp.encode(layoutPositions(goal.Layout))}
+	default:
+		states = sp.gathered()
+	}
+	return sp.withPoles(states)
+}
+
+// withPoles returns given positions with disks of every peg lying either way
+// up, positions are returned as is when disks have no poles.
+func (sp *stateSpace) withPoles(states []uint64) []uint64 {
+	if sp.magnets == nil {
+		return states
+	}
+
+	var all []uint64
+	for _, s := range states {
+		occupied := 0
+		for d := range sp.disks {
+			occupied |= 1 << sp.peg(s, d)
+		}
+		// every subset of occupied pegs has south pole up once
+		for m := occupied; ; m = (m - 1) & occupied {
+			all = append(all, s+uint64(m)*sp.polePow)
+			if m == 0 {
+				break
+			}
+		}
+	}
+	return all
+}
+
+// south tells whether disks of peg p have south pole up.
+func (sp *stateSpace) south(s uint64, p int) bool {
+	return s/sp.polePow>>p&1 == 1
+}
+
+// dealt tells whether disks lie the way they are dealt by the rules at the
+// start of a game.
+func (sp *stateSpace) dealt(s uint64) bool {
+	if sp.magnets == nil {
+		return true
+	}
+	for d := range sp.disks {
+		p := sp.peg(s, d)
+		if sp.south(s, p) != (sp.magnets.pole(p) == South) {
+			return false
+		}
+	}
+	return true
+}
+
+// gathered returns all positions with every disk on a single peg.
+func (sp *stateSpace) gathered() []uint64 {
+	states := make([]uint64, sp.pegs)
+	for t := range sp.pegs {
+		for i := range sp.disks {
+			states[t] += uint64(t) * sp.pow[i]
+		}
+	}
+	return states
+}
+
+// layout unpacks position, disks are put from the biggest one.
+func (sp *stateSpace) layout(s uint64) Layout {
+	l := make(Layout, sp.pegs)
+	for d := sp.disks - 1; d >= 0; d-- {
+		p := sp.peg(s, d)
+		l[p] = append(l[p], uint(d+1))
+	}
+	return l
+}
+
+// neighbours calls fn for every position reachable with a single move. When
+// reverse is set it is every position s is reachable from instead, it is
+// the same unless moves are one-way.
+func (sp *stateSpace) neighbours(s uint64, top []int, reverse bool, fn func(uint64)) {
+	for i := range top {
+		top[i] = -1
+	}
+	for d := sp.disks - 1; d >= 0; d-- {
+		top[sp.peg(s, d)] = d
+	}
+
+	for from, d := range top {
+		if d == -1 {
+			continue
+		}
+		for to, other := range top {
+			if to == from || (other != -1 && other < d) {
+				continue
+			}
+			if sp.moves != nil {
+				src, dst := from, to
+				if reverse {
+					src, dst = to, from
+				}
+				if !sp.moves.canMove(src, dst, sp.pegs) {
+					continue
+				}
+			}
+			n := s - uint64(from)*sp.pow[d] + uint64(to)*sp.pow[d]
+			if sp.magnets != nil {
+				// The disk turns over, so it may only join disks lying
+				// the other way up. Moves stay reversible.
+				south := sp.south(s, from)
+				if other != -1 && sp.south(s, to) == south {
+					continue
+				}
+				if other == -1 && !south {
+					n += sp.polePow << to
+				}
+				if south && sp.alone(s, d, from) {
+					n -= sp.polePow << from
+				}
+			}
+			fn(n)
+		}
+	}
+}
+
+// alone tells whether disk d is the only one on peg p.
+func (sp *stateSpace) alone(s uint64, d int, p int) bool {
+	for other := range sp.disks {
+		if other != d && sp.peg(s, other) == p {
+			return false
+		}
+	}
+	return true
+}
+
+// move tells which move turns one position into another.
+func (sp *stateSpace) move(from uint64, to uint64) Move {
+	for d := range sp.disks {
+		if a, b := sp.peg(from, d), sp.peg(to, d); a != b {
+			return Move{From: a, To: b}
+		}
+	}
+	return Move{}
+}
+
+type visit struct {
+	parent uint64
+	depth  int
+}
+
+func (sp *stateSpace) search(start uint64, goals []uint64, maxStates int) ([]Move, error) {
+	fwd := map[uint64]visit{start: {parent: start}}
+	bwd := make(map[uint64]visit, len(goals))
+	for _, s := range goals {
+		if s == start {
+			return []Move{}, nil
+		}
+		bwd[s] = visit{parent: s}
+	}
+
+	fwdLayer := []uint64{start}
+	bwdLayer := goals
+	top := make([]int, sp.pegs)
+
+	for len(fwdLayer) > 0 && len(bwdLayer) > 0 {
+		// Always grow the smaller side, it keeps both searches shallow.
+		layer, seen, other, reverse := &fwdLayer, fwd, bwd, false
+		if len(bwdLayer) < len(fwdLayer) {
+			layer, seen, other, reverse = &bwdLayer, bwd, fwd, true
+		}
+
+		var next []uint64
+		meet, best := uint64(0), -1
+		for _, s := range *layer {
+			depth := seen[s].depth + 1
+			sp.neighbours(s, top, reverse, func(n uint64) {
+				if _, ok := seen[n]; ok {
+					return
+				}
+				seen[n] = visit{parent: s, depth: depth}
+				next = append(next, n)
+
+				// Other side may have reached n at different depths,
+				// the whole layer is checked to pick the shortest path.
+				if v, ok := other[n]; ok && (best == -1 || depth+v.depth < best) {
+					meet, best = n, depth+v.depth
+				}
+			})
+
+			if len(fwd)+len(bwd) > maxStates {
+				return nil, fmt.Errorf("%w: more than %d positions visited", ErrStateSpaceTooLarge, maxStates)
+			}
+		}
+
+		if best != -1 {
+			return sp.path(meet, fwd, bwd), nil
+		}
+		*layer = next
+	}
+
+	return nil, ErrNoSolution
+}
+
+// layer returns every position which needs exactly depth moves to reach the
+// closest of given ones.
+func (sp *stateSpace) layer(from []uint64, depth int, maxStates int) ([]uint64, error) {
+	seen := make(map[uint64]struct{}, len(from))
+	for _, s := range from {
+		seen[s] = struct{}{}
+	}
+
+	layer := append([]uint64{}, from...)
+	top := make([]int, sp.pegs)
+	for range depth {
+		var next []uint64
+		for _, s := range layer {
+			sp.neighbours(s, top, true, func(n uint64) {
+				if _, ok := seen[n]; ok {
+					return
+				}
+				seen[n] = struct{}{}
+				next = append(next, n)
+			})
+
+			if len(seen) > maxStates {
+				return nil, fmt.Errorf("%w: more than %d positions visited", ErrStateSpaceTooLarge, maxStates)
+			}
+		}
+		layer = next
+	}
+
+	return layer, nil
+}
+
+// path joins both halves of the search at meeting position.
+func (sp *stateSpace) path(meet uint64, fwd map[uint64]visit, bwd map[uint64]visit) []Move {
+	var head []Move
+	for s := meet; fwd[s].parent != s; s = fwd[s].parent {
+		head = append(head, sp.move(fwd[s].parent, s))
+	}
+
+	moves := make([]Move, 0, len(head)+bwd[meet].depth)
+	for i := len(head) - 1; i >= 0; i-- {
+		moves = append(moves, head[i])
+	}
+	for s := meet; bwd[s].parent != s; s = bwd[s].parent {
+		moves = append(moves, sp.move(s, bwd[s].parent))
+	}
+
+	return moves
+}
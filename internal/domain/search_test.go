@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSolveExactTowerOntoBiggestDisk(t *testing.T) {
+	for pegs := 3; pegs <= 5; pegs++ {
+		for disks := uint(1); disks <= 8; disks++ {
+			t.Run(fmt.Sprintf("%d pegs %d disks", pegs, disks), func(t *testing.T) {
+				p := make([][]uint, pegs)
+				p[0] = []uint{disks}
+				for i := uint(1); i < disks; i++ {
+					p[1] = append(p[1], disks-i)
+				}
+				g := buildGame(t, p)
+
+				moves, err := SolveExact(g, 0)
+				require.NoError(t, err)
+
+				assert.Equal(t, FrameStewartMoves(uint(pegs), disks-1), uint64(len(moves)))
+				applyMoves(t, g, moves)
+				assert.True(t, g.IsWon())
+			})
+		}
+	}
+}
+
+func TestSolveExactRandomStart(t *testing.T) {
+	for pegs := uint(3); pegs <= 5; pegs++ {
+		for disks := uint(1); disks <= 7; disks++ {
+			t.Run(fmt.Sprintf("%d pegs %d disks", pegs, disks), func(t *testing.T) {
+				g, err := NewGame(pegs, disks, &Player{}, DefaultColorPicker())
+				require.NoError(t, err)
+
+				exact, err := SolveExact(g, 0)
+				require.NoError(t, err)
+				heuristic, err := Solve(g)
+				require.NoError(t, err)
+
+				assert.LessOrEqual(t, len(exact), len(heuristic))
+				applyMoves(t, g, exact)
+				assert.True(t, g.IsWon())
+			})
+		}
+	}
+}
+
+func TestSolveExactIsShortest(t *testing.T) {
+	for pegs := uint(3); pegs <= 4; pegs++ {
+		for disks := uint(1); disks <= 5; disks++ {
+			for i := range 10 {
+				t.Run(fmt.Sprintf("%d pegs %d disks #%d", pegs, disks, i), func(t *testing.T) {
+					g, err := NewGame(pegs, disks, &Player{}, DefaultColorPicker())
+					require.NoError(t, err)
+
+					moves, err := SolveExact(g, 0)
+					require.NoError(t, err)
+
+					assert.Equal(t, shortestPath(t, g), len(moves))
+				})
+			}
+		}
+	}
+}
+
+func TestBoardFits(t *testing.T) {
+	tests := []struct {
+		pegs  uint
+		disks uint
+		rules Ruleset
+		want  bool
+	}{
+		{3, 12, StandardRuleset{}, true},
+		{3, 13, StandardRuleset{}, false},
+		{4, 10, AdjacentRuleset{}, true},
+		{5, 10, AdjacentRuleset{}, false},
+		{3, 10, MagneticRuleset{}, true},
+		{3, 11, MagneticRuleset{}, false},
+		{40, 64, StandardRuleset{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d pegs %d disks %s", tt.pegs, tt.disks, tt.rules.Name()), func(t *testing.T) {
+			g, err := NewGame(tt.pegs, tt.disks, &Player{}, DefaultColorPicker(), WithStartLayout(ClassicStart), WithRuleset(tt.rules))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, boardFits(g, hintMaxStates))
+		})
+	}
+}
+
+func TestSolveExactAlreadyWon(t *testing.T) {
+	moves, err := SolveExact(buildGame(t, classicPegs(3, 5)), 0)
+	require.NoError(t, err)
+	assert.Empty(t, moves)
+}
+
+func TestSolveExactLimits(t *testing.T) {
+	g := buildGame(t, [][]uint{{10}, {9, 8, 7, 6, 5, 4, 3, 2, 1}, {}, {}})
+
+	_, err := SolveExact(g, 100)
+	assert.True(t, errors.Is(err, ErrStateSpaceTooLarge))
+
+	_, err = SolveExact(buildGame(t, classicPegs(100, 1000)), 0)
+	assert.True(t, errors.Is(err, ErrStateSpaceTooLarge))
+
+	_, err = SolveExact(buildGame(t, [][]uint{{2}, {1}}), 0)
+	assert.NoError(t, err)
+
+	_, err = SolveExact(buildGame(t, [][]uint{{2}, {3, 1}}), 0)
+	assert.True(t, errors.Is(err, ErrNoSolution))
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var ErrGameNotFound = errors.New("game is not found")
+
+// GameID identifies a stored game. It is random so games of other players
+// cannot be guessed.
+type GameID string
+
+func NewGameID() GameID {
+	b := make([]byte, 8)
+	// crypto/rand never fails on supported platforms
+	_, _ = rand.Read(b)
+	return GameID(hex.EncodeToString(b))
+}
+
+// GameRepository keeps games between requests and restarts. Games are
+// stored as recordings, so GetByID always returns a fresh copy and changes
+// have to be saved back.
+type GameRepository interface {
+	// Save creates or updates the game and marks it as touched now
+	Save(ctx context.Context, id GameID, g *Game) error
+	GetByID(ctx context.Context, id GameID) (*Game, error)
+	Delete(ctx context.Context, id GameID) error
+	// DeleteExpired removes games not touched since given time and returns
+	// how many were removed
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
@@ -0,0 +1,290 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrUnsupportedPegs = errors.New("solver needs at least 3 pegs")
+var ErrSolutionTooLong = errors.New("solution is too long to be generated")
+var ErrInvalidPosition = errors.New("game position is invalid")
+
+// maxSolutionMoves limits how many moves a solver is allowed to produce.
+// 2^22 moves is about 22 disks on three pegs.
+const maxSolutionMoves = 1 << 22
+
+// Move is a single transfer of the top disk of peg From onto peg To.
+// It can be fed straight back into Game.MoveDisk.
+type Move struct {
+	From int
+	To   int
+}
+
+// Solve returns a sequence of moves which brings the game to a won position
+// according to its goal and rules. Game itself is not modified.
+func Solve(g *Game) ([]Move, error) {
+	return g.rules().Solve(g)
+}
+
+// solveStandard solves the game under StandardRuleset.
+//
+// Disks may be scattered across pegs in any legal way. For three pegs the
+// solution is the shortest one. With more pegs towers are moved by
+// Frame–Stewart algorithm, which is presumed optimal for the classic start.
+func solveStandard(g *Game) ([]Move, error) {
+	if len(g.Pegs) < 3 {
+		return nil, ErrUnsupportedPegs
+	}
+
+	pos, err := diskPositions(g)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.Goal.reached(pos) {
+		return []Move{}, nil
+	}
+
+	if g.Goal.Kind == GoalLayout {
+		goal := layoutPositions(g.Goal.Layout)
+		if len(g.Pegs) == 3 {
+			return solveThreePegsToLayout(pos, goal)
+		}
+		return solveFrameStewartToLayout(pos, goal, len(g.Pegs))
+	}
+
+	targets := g.Goal.targets(len(g.Pegs))
+	if len(g.Pegs) == 3 {
+		return solveThreePegs(pos, targets)
+	}
+	return solveFrameStewart(pos, len(g.Pegs), targets)
+}
+
+// diskPositions returns peg index for every disk, so pos[id-1] is the peg
+// holding disk of given ID.
+func diskPositions(g *Game) ([]int, error) {
+	pos := make([]int, g.TotalDisks)
+	for i := range pos {
+		pos[i] = -1
+	}
+
+	for i, p := range g.Pegs {
+		for d := p.TopDisk; d != nil; d = d.Next {
+			if d.ID < 1 || int(d.ID) > g.TotalDisks || pos[d.ID-1] != -1 {
+				return nil, fmt.Errorf("%w: unexpected disk %d", ErrInvalidPosition, d.ID)
+			}
+			if d.Next != nil && d.Size > d.Next.Size {
+				return nil, fmt.Errorf("%w: disk %d lies on smaller one", ErrInvalidPosition, d.ID)
+			}
+			pos[d.ID-1] = i
+		}
+	}
+
+	for i, p := range pos {
+		if p == -1 {
+			return nil, fmt.Errorf("%w: disk of size %d is missing", ErrInvalidPosition, i+1)
+		}
+	}
+
+	return pos, nil
+}
+
+// solveThreePegs gathers disks on one of target pegs which gives the
+// shortest path.
+//
+// All disks smaller than the biggest misplaced one are first gathered onto
+// the spare peg, then the disk goes to its place and the gathered tower is
+// moved on top of it. This greedy recursion is optimal for three pegs,
+// so we only pick the target peg.
+func solveThreePegs(pos []int, targets []int) ([]Move, error) {
+	target := targets[0]
+	best := gatherCost(pos, target)
+	for _, t := range targets[1:] {
+		if c := gatherCost(pos, t); c < best {
+			best, target = c, t
+		}
+	}
+
+	if best > maxSolutionMoves {
+		return nil, ErrSolutionTooLong
+	}
+
+	s := newSolver(pos, 3)
+	s.moves = make([]Move, 0, best)
+	s.gather(len(pos), target)
+
+	return s.moves, nil
+}
+
+// solveThreePegsToLayout moves disks from one arbitrary position to another.
+//
+// Disks which are already in place at the bottom never move. The biggest
+// misplaced disk either goes straight to its peg while smaller ones wait on
+// the third one, or it stops at the third peg first to let the smaller ones
+// pass. The shortest solution is always one of these two.
+func solveThreePegsToLayout(pos []int, goal []int) ([]Move, error) {
+	n := len(pos)
+	for n > 0 && pos[n-1] == goal[n-1] {
+		n--
+	}
+	if n == 0 {
+		return []Move{}, nil
+	}
+
+	from, to := pos[n-1], goal[n-1]
+	spare := 3 - from - to
+
+	var tower uint64 = maxSolutionMoves + 1
+	if n-1 < 63 {
+		tower = 1<<(n-1) - 1
+	}
+
+	direct := satAdd(satAdd(gatherCost(pos[:n-1], spare), 1), gatherCost(goal[:n-1], spare))
+	twice := satAdd(satAdd(satAdd(gatherCost(pos[:n-1], to), 2), tower), gatherCost(goal[:n-1], from))
+
+	best := min(direct, twice)
+	if best > maxSolutionMoves {
+		return nil, ErrSolutionTooLong
+	}
+
+	s := newSolver(pos, 3)
+	s.moves = make([]Move, 0, best)
+	all := []int{0, 1, 2}
+	if direct <= twice {
+		s.gather(n-1, spare)
+		s.move(n, to)
+		s.scatter(goal, n-1, spare)
+	} else {
+		s.gather(n-1, to)
+		s.move(n, spare)
+		s.transfer(1, n-1, to, from, all)
+		s.move(n, to)
+		s.scatter(goal, n-1, from)
+	}
+
+	return s.moves, nil
+}
+
+// gatherCost is the number of moves needed to stack all disks on peg t with
+// three pegs. Saturates at maxSolutionMoves+1, we are not going to generate
+// more anyway.
+func gatherCost(pos []int, t int) uint64 {
+	var cost uint64
+	for n := len(pos); n >= 1; n-- {
+		if pos[n-1] == t {
+			continue
+		}
+		if n-1 >= 63 {
+			return maxSolutionMoves + 1
+		}
+		cost += 1 << (n - 1)
+		if cost > maxSolutionMoves {
+			return maxSolutionMoves + 1
+		}
+		t = 3 - pos[n-1] - t
+	}
+
+	return cost
+}
+
+type solver struct {
+	pos   []int
+	pegs  int
+	moves []Move
+
+	// split remembers how many top disks Frame–Stewart puts aside when
+	// moving a tower of n disks with k pegs.
+	split map[[2]int]int
+}
+
+func newSolver(pos []int, pegs int) *solver {
+	return &solver{
+		pos:   append([]int(nil), pos...),
+		pegs:  pegs,
+		split: make(map[[2]int]int),
+	}
+}
+
+// gather stacks disks 1..n on peg t wherever they are right now. Works only
+// for three pegs.
+func (s *solver) gather(n int, t int) {
+	if n == 0 {
+		return
+	}
+
+	from := s.pos[n-1]
+	if from == t {
+		s.gather(n-1, t)
+		return
+	}
+
+	spare := 3 - from - t
+	s.gather(n-1, spare)
+	s.move(n, t)
+	s.transfer(1, n-1, spare, t, []int{0, 1, 2})
+}
+
+// scatter moves tower of disks 1..n from peg "from" to their places in
+// goal. It is gathering them from goal onto "from" played backwards.
+func (s *solver) scatter(goal []int, n int, from int) {
+	r := newSolver(goal, s.pegs)
+	r.gather(n, from)
+	s.unwind(r.moves)
+}
+
+// unwind plays given moves backwards.
+func (s *solver) unwind(moves []Move) {
+	for i := len(moves) - 1; i >= 0; i-- {
+		s.apply(Move{From: moves[i].To, To: moves[i].From})
+	}
+}
+
+// apply moves the top disk of m.From.
+func (s *solver) apply(m Move) {
+	for d, p := range s.pos {
+		if p == m.From {
+			s.move(d+1, m.To)
+			return
+		}
+	}
+}
+
+// transfer moves tower of disks lo..hi from peg "from" onto peg "to" using
+// only given pegs. Tower is split into two parts: top one is put aside on
+// some spare peg, the bottom one is moved without that peg and then the top
+// is put back on it.
+func (s *solver) transfer(lo int, hi int, from int, to int, pegs []int) {
+	n := hi - lo + 1
+	if n <= 0 {
+		return
+	}
+	if n == 1 {
+		s.move(lo, to)
+		return
+	}
+
+	k := min(len(pegs), n+1)
+	l := n - 1
+	if k > 3 {
+		l = s.splitFor(k, n)
+	}
+
+	aside := -1
+	rest := make([]int, 0, len(pegs)-1)
+	for _, p := range pegs {
+		if aside == -1 && p != from && p != to {
+			aside = p
+			continue
+		}
+		rest = append(rest, p)
+	}
+
+	s.transfer(lo, lo+l-1, from, aside, pegs)
+	s.transfer(lo+l, hi, from, to, rest)
+	s.transfer(lo, lo+l-1, aside, to, pegs)
+}
+
+func (s *solver) move(disk int, to int) {
+	s.moves = append(s.moves, Move{From: s.pos[disk-1], To: to})
+	s.pos[disk-1] = to
+}
@@ -0,0 +1,296 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildGame creates game with given disks on every peg, listed bottom to top.
+func buildGame(t *testing.T, pegs [][]uint) *Game {
+	t.Helper()
+
+	g := &Game{Pegs: make([]Peg, len(pegs)), Player: &Player{}}
+	for i, sizes := range pegs {
+		for _, size := range sizes {
+			require.NoError(t, g.Pegs[i].PutDisk(&Disk{ID: size, Size: size}))
+			g.TotalDisks++
+		}
+	}
+
+	return g
+}
+
+func classicPegs(pegs int, disks uint) [][]uint {
+	p := make([][]uint, pegs)
+	for i := range disks {
+		p[0] = append(p[0], disks-i)
+	}
+	return p
+}
+
+func applyMoves(t *testing.T, g *Game, moves []Move) {
+	t.Helper()
+
+	for i, m := range moves {
+		require.NoError(t, g.MoveDisk(m.From, m.To), "move #%d %+v", i, m)
+	}
+}
+
+func TestSolveClassicStart(t *testing.T) {
+	for disks := uint(1); disks <= 12; disks++ {
+		t.Run(fmt.Sprintf("%d disks", disks), func(t *testing.T) {
+			g := buildGame(t, classicPegs(3, disks))
+
+			moves, err := Solve(g)
+			require.NoError(t, err)
+
+			// Any peg counts as a win, so there is nothing to do.
+			assert.Empty(t, moves)
+		})
+	}
+}
+
+func TestSolveRandomStart(t *testing.T) {
+	for disks := uint(1); disks <= 10; disks++ {
+		t.Run(fmt.Sprintf("%d disks", disks), func(t *testing.T) {
+			g, err := NewGame(3, disks, &Player{}, DefaultColorPicker())
+			require.NoError(t, err)
+
+			moves, err := Solve(g)
+			require.NoError(t, err)
+
+			assert.LessOrEqual(t, len(moves), 1<<disks-1)
+			applyMoves(t, g, moves)
+			assert.True(t, g.IsWon())
+		})
+	}
+}
+
+func TestSolveTowerOntoBiggestDisk(t *testing.T) {
+	for disks := uint(1); disks <= 12; disks++ {
+		t.Run(fmt.Sprintf("%d disks", disks), func(t *testing.T) {
+			pegs := [][]uint{{}, {}, {}}
+			pegs[0] = append(pegs[0], disks)
+			for i := uint(1); i < disks; i++ {
+				pegs[2] = append(pegs[2], disks-i)
+			}
+			g := buildGame(t, pegs)
+
+			moves, err := Solve(g)
+			require.NoError(t, err)
+
+			assert.Equal(t, 1<<(disks-1)-1, len(moves))
+			applyMoves(t, g, moves)
+			assert.True(t, g.IsWon())
+		})
+	}
+}
+
+func TestSolveTowerToSpecificPeg(t *testing.T) {
+	for disks := uint(1); disks <= 12; disks++ {
+		t.Run(fmt.Sprintf("%d disks", disks), func(t *testing.T) {
+			g := buildGame(t, classicPegs(3, disks))
+			s := newSolver(make([]int, disks), 3)
+
+			s.gather(int(disks), 2)
+
+			assert.Equal(t, 1<<disks-1, len(s.moves))
+			applyMoves(t, g, s.moves)
+			assert.True(t, g.IsWon())
+			assert.Equal(t, int(disks), int(g.Pegs[2].totalDisks))
+		})
+	}
+}
+
+func TestSolveErrors(t *testing.T) {
+	_, err := Solve(buildGame(t, classicPegs(2, 3)))
+	assert.True(t, errors.Is(err, ErrUnsupportedPegs))
+
+	_, err = Solve(buildGame(t, [][]uint{{1, 2}, {}, {3}}))
+	assert.True(t, errors.Is(err, ErrInvalidPosition))
+
+	_, err = Solve(buildGame(t, [][]uint{{1}, {64}, {}}))
+	assert.True(t, errors.Is(err, ErrInvalidPosition))
+
+	tower := make([]uint, 0, 39)
+	for i := uint(39); i >= 1; i-- {
+		tower = append(tower, i)
+	}
+	_, err = Solve(buildGame(t, [][]uint{{40}, tower, {}}))
+	assert.True(t, errors.Is(err, ErrSolutionTooLong))
+
+	_, err = Solve(buildGame(t, [][]uint{{40}, tower, {}, {}}))
+	assert.True(t, errors.Is(err, ErrBoardTooLarge))
+}
+
+// shortestPath finds length of the shortest solution by brute force, every
+// disk stacked on any single peg is won.
+func shortestPath(t *testing.T, g *Game) int {
+	t.Helper()
+
+	start, err := diskPositions(g)
+	require.NoError(t, err)
+
+	key := func(pos []int) string { return fmt.Sprint(pos) }
+	won := func(pos []int) bool {
+		for _, p := range pos {
+			if p != pos[0] {
+				return false
+			}
+		}
+		return true
+	}
+
+	seen := map[string]struct{}{key(start): {}}
+	layer := [][]int{start}
+	for depth := 0; len(layer) > 0; depth++ {
+		var next [][]int
+		for _, pos := range layer {
+			if won(pos) {
+				return depth
+			}
+
+			top := make([]int, len(g.Pegs))
+			for i := range top {
+				top[i] = -1
+			}
+			for d := len(pos) - 1; d >= 0; d-- {
+				top[pos[d]] = d
+			}
+			for from := range top {
+				for to := range top {
+					if top[from] == -1 || from == to || (top[to] != -1 && top[to] < top[from]) {
+						continue
+					}
+					n := append([]int(nil), pos...)
+					n[top[from]] = to
+					if _, ok := seen[key(n)]; !ok {
+						seen[key(n)] = struct{}{}
+						next = append(next, n)
+					}
+				}
+			}
+		}
+		layer = next
+	}
+
+	t.Fatal("no solution found")
+	return 0
+}
+
+func TestSolveIsShortest(t *testing.T) {
+	for disks := uint(1); disks <= 6; disks++ {
+		for i := range 20 {
+			t.Run(fmt.Sprintf("%d disks #%d", disks, i), func(t *testing.T) {
+				g, err := NewGame(3, disks, &Player{}, DefaultColorPicker())
+				require.NoError(t, err)
+
+				moves, err := Solve(g)
+				require.NoError(t, err)
+
+				assert.Equal(t, shortestPath(t, g), len(moves))
+			})
+		}
+	}
+}
+
+// frameStewart is the plain recurrence, closed form must agree with it.
+func frameStewart(memo map[[2]uint]uint64, pegs uint, disks uint) uint64 {
+	if disks <= 1 {
+		return uint64(disks)
+	}
+	if pegs == 3 {
+		return 1<<disks - 1
+	}
+	if c, ok := memo[[2]uint{pegs, disks}]; ok {
+		return c
+	}
+
+	best := uint64(math.MaxUint64)
+	for l := uint(1); l < disks; l++ {
+		best = min(best, 2*frameStewart(memo, pegs, l)+frameStewart(memo, pegs-1, disks-l))
+	}
+	memo[[2]uint{pegs, disks}] = best
+
+	return best
+}
+
+func TestFrameStewartMoves(t *testing.T) {
+	assert.Equal(t, []uint64{0, 1, 3, 5, 9, 13, 17, 25, 33, 41, 49}, func() []uint64 {
+		var got []uint64
+		for disks := range uint(11) {
+			got = append(got, FrameStewartMoves(4, disks))
+		}
+		return got
+	}())
+
+	memo := make(map[[2]uint]uint64)
+	for pegs := uint(3); pegs <= 8; pegs++ {
+		for disks := uint(0); disks <= 40; disks++ {
+			assert.Equal(t, frameStewart(memo, pegs, disks), FrameStewartMoves(pegs, disks), "%d pegs, %d disks", pegs, disks)
+		}
+	}
+
+	assert.Equal(t, uint64(math.MaxUint64), FrameStewartMoves(3, 64))
+	assert.Equal(t, uint64(1<<63-1), FrameStewartMoves(3, 63))
+	assert.Equal(t, uint64(math.MaxUint64), FrameStewartMoves(2, 2))
+	assert.Equal(t, uint64(197), FrameStewartMoves(100, 99))
+	assert.Equal(t, uint64(201), FrameStewartMoves(100, 100))
+}
+
+func TestSolveFrameStewartTower(t *testing.T) {
+	for pegs := 4; pegs <= 6; pegs++ {
+		for disks := uint(1); disks <= 20; disks++ {
+			t.Run(fmt.Sprintf("%d pegs %d disks", pegs, disks), func(t *testing.T) {
+				g := buildGame(t, classicPegs(pegs, disks))
+				pos := make([]int, disks)
+				all := make([]int, pegs)
+				for i := range all {
+					all[i] = i
+				}
+				s := newSolver(pos, pegs)
+
+				s.gatherFS(newFSPlanner(pos), 1, int(disks), pegs-1, all)
+
+				assert.Equal(t, FrameStewartMoves(uint(pegs), disks), uint64(len(s.moves)))
+				applyMoves(t, g, s.moves)
+				assert.True(t, g.IsWon())
+				assert.Equal(t, int(disks), int(g.Pegs[pegs-1].totalDisks))
+			})
+		}
+	}
+}
+
+func TestSolveManyPegsRandomStart(t *testing.T) {
+	for pegs := uint(4); pegs <= 7; pegs++ {
+		for disks := uint(1); disks <= 15; disks++ {
+			t.Run(fmt.Sprintf("%d pegs %d disks", pegs, disks), func(t *testing.T) {
+				g, err := NewGame(pegs, disks, &Player{}, DefaultColorPicker())
+				require.NoError(t, err)
+
+				moves, err := Solve(g)
+				require.NoError(t, err)
+
+				applyMoves(t, g, moves)
+				assert.True(t, g.IsWon())
+			})
+		}
+	}
+}
+
+func TestSolveHugeBoard(t *testing.T) {
+	g, err := NewGame(100, 1000, &Player{}, DefaultColorPicker())
+	require.NoError(t, err)
+
+	_, err = Solve(g)
+	assert.True(t, errors.Is(err, ErrBoardTooLarge))
+
+	moves, err := Solve(buildGame(t, classicPegs(100, 1000)))
+	require.NoError(t, err)
+	assert.Empty(t, moves)
+}
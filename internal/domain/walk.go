@@ -0,0 +1,88 @@
+package domain
+
+// walkSolver moves disks when they may go only to some neighbouring pegs.
+// Disks are placed from the biggest one, every disk walks peg by peg while
+// smaller ones wait on a spare peg out of its way.
+type walkSolver struct {
+	pos   []int
+	moves []Move
+	// step is the next peg on the way from p to t
+	step func(p int, t int) int
+	// spare is where smaller disks wait while a disk goes from p to q
+	spare   func(p int, q int) int
+	tooLong bool
+}
+
+// solve moves disks from s.pos to goal.
+func (s *walkSolver) solve(goal []int) ([]Move, error) {
+	s.pos = append([]int(nil), s.pos...)
+	s.moves = []Move{}
+	for n := len(s.pos); n >= 1; n-- {
+		s.place(n, goal[n-1])
+	}
+
+	if s.tooLong {
+		return nil, ErrSolutionTooLong
+	}
+	return s.moves, nil
+}
+
+// gather stacks disks 1..n on peg t.
+func (s *walkSolver) gather(n int, t int) {
+	for ; n >= 1 && !s.tooLong; n-- {
+		s.place(n, t)
+	}
+}
+
+// place brings disk n to peg t.
+func (s *walkSolver) place(n int, t int) {
+	for p := s.pos[n-1]; p != t && !s.tooLong; p = s.pos[n-1] {
+		q := s.step(p, t)
+		s.gather(n-1, s.spare(p, q))
+		s.move(n, q)
+	}
+}
+
+func (s *walkSolver) move(disk int, to int) {
+	if s.tooLong {
+		return
+	}
+	if len(s.moves) >= maxSolutionMoves {
+		s.tooLong = true
+		return
+	}
+
+	s.moves = append(s.moves, Move{From: s.pos[disk-1], To: to})
+	s.pos[disk-1] = to
+}
+
+// solveWalking solves the game with given solver, picking the target which
+// gives the shortest solution when the goal allows any.
+func solveWalking(g *Game, newSolver func(pos []int) *walkSolver) ([]Move, error) {
+	pos, err := diskPositions(g)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.Goal.Kind == GoalLayout {
+		return newSolver(pos).solve(layoutPositions(g.Goal.Layout))
+	}
+
+	var best []Move
+	for _, t := range g.Goal.targets(len(g.Pegs)) {
+		goal := make([]int, len(pos))
+		for i := range goal {
+			goal[i] = t
+		}
+
+		moves, err := newSolver(pos).solve(goal)
+		if err != nil {
+			return nil, err
+		}
+		if best == nil || len(moves) < len(best) {
+			best = moves
+		}
+	}
+
+	return best, nil
+}
@@ -0,0 +1,94 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/domain"
+)
+
+type gameSession struct {
+	player    domain.Player
+	recording domain.Recording
+	updatedAt time.Time
+}
+
+type gameInmemoryRepo struct {
+	games  map[domain.GameID]gameSession
+	lock   sync.RWMutex
+	logger *slog.Logger
+}
+
+func NewGameInmemoryRepo(logger *slog.Logger) *gameInmemoryRepo {
+	return &gameInmemoryRepo{
+		games:  make(map[domain.GameID]gameSession),
+		lock:   sync.RWMutex{},
+		logger: logger,
+	}
+}
+
+func (r *gameInmemoryRepo) Save(ctx context.Context, id domain.GameID, g *domain.Game) error {
+	if g.Player == nil {
+		return domain.ErrPlayerCannotBeNil
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.games[id] = gameSession{
+		player:    *g.Player,
+		recording: g.Recording(),
+		updatedAt: time.Now(),
+	}
+
+	return nil
+}
+
+func (r *gameInmemoryRepo) GetByID(ctx context.Context, id domain.GameID) (*domain.Game, error) {
+	r.lock.RLock()
+	s, ok := r.games[id]
+	r.lock.RUnlock()
+
+	if !ok {
+		return nil, domain.ErrGameNotFound
+	}
+
+	player := s.player
+	g, err := domain.Replay(s.recording, &player, domain.DefaultColorPicker())
+	if err != nil {
+		r.logger.Error("cannot restore game", slog.String("id", string(id)), slog.Any("err", err))
+		return nil, fmt.Errorf("cannot restore game: %w", err)
+	}
+
+	return g, nil
+}
+
+func (r *gameInmemoryRepo) Delete(ctx context.Context, id domain.GameID) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.games[id]; !ok {
+		return domain.ErrGameNotFound
+	}
+	delete(r.games, id)
+
+	return nil
+}
+
+func (r *gameInmemoryRepo) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var n int
+	for id, s := range r.games {
+		if s.updatedAt.Before(before) {
+			delete(r.games, id)
+			n++
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,84 @@
+package inmemory
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/domain"
+)
+
+// defaultRecordsLimit is used when filter has no limit.
+const defaultRecordsLimit = 10
+
+type recordInmemoryRepo struct {
+	records []domain.Record
+	lock    sync.RWMutex
+	logger  *slog.Logger
+}
+
+func NewRecordInmemoryRepo(logger *slog.Logger) *recordInmemoryRepo {
+	return &recordInmemoryRepo{
+		records: make([]domain.Record, 0),
+		lock:    sync.RWMutex{},
+		logger:  logger,
+	}
+}
+
+func (r *recordInmemoryRepo) Save(ctx context.Context, rec *domain.Record) (domain.RecordID, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	saved := *rec
+	saved.ID = domain.RecordID(len(r.records) + 1)
+	r.records = append(r.records, saved)
+
+	r.logger.Info("record successfully saved",
+		slog.Int("id", int(saved.ID)),
+		slog.Int("player_id", int(saved.PlayerID)),
+	)
+
+	return saved.ID, nil
+}
+
+func (r *recordInmemoryRepo) GetTop(ctx context.Context, filter domain.RecordFilter) ([]*domain.Record, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	records := []*domain.Record{}
+	for _, rec := range r.records {
+		if filter.Pegs != 0 && rec.Pegs != filter.Pegs {
+			continue
+		}
+		if filter.Disks != 0 && rec.Disks != filter.Disks {
+			continue
+		}
+		if filter.Ruleset != "" && rec.Ruleset != filter.Ruleset {
+			continue
+		}
+		if filter.Goal != "" && rec.Goal != filter.Goal {
+			continue
+		}
+		records = append(records, &rec)
+	}
+
+	slices.SortStableFunc(records, func(a, b *domain.Record) int {
+		return cmp.Or(
+			cmp.Compare(a.Steps, b.Steps),
+			cmp.Compare(a.HintsUsed, b.HintsUsed),
+			a.AchievedAt.Compare(b.AchievedAt),
+		)
+	})
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultRecordsLimit
+	}
+	if len(records) > limit {
+		records = records[:limit]
+	}
+
+	return records, nil
+}
@@ -33,6 +33,10 @@ func (r *playerInmemoryRepo) Save(ctx context.Context, nickname string) (domain.
 	r.nameToIDLock.Lock()
 	defer r.nameToIDLock.Unlock()
 
+	if _, ok := r.nameToID[nickname]; ok {
+		return 0, &domain.ErrCannotCreatePlayer{Nickname: nickname, Reason: "name is already taken"}
+	}
+
 	id := domain.PlayerID(len(r.users) + 1)
 	// TODO: generate ID
 	r.users[id] = domain.Player{ID: id, Nickname: nickname}
@@ -0,0 +1,21 @@
+package inmemory
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/infrastructure/persistance/repotest"
+)
+
+func TestContract(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	repotest.Run(t, func(t *testing.T) repotest.Repos {
+		return repotest.Repos{
+			Players: NewPlayerInmemoryRepo(logger),
+			Records: NewRecordInmemoryRepo(logger),
+			Games:   NewGameInmemoryRepo(logger),
+		}
+	})
+}
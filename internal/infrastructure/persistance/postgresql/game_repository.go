@@ -0,0 +1,122 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/domain"
+)
+
+type gamePostgresRepo struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewGamePostgresRepo(logger *slog.Logger, db *sql.DB) *gamePostgresRepo {
+	return &gamePostgresRepo{db: db, logger: logger}
+}
+
+func (r *gamePostgresRepo) Save(ctx context.Context, id domain.GameID, g *domain.Game) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if g.Player == nil {
+		return domain.ErrPlayerCannotBeNil
+	}
+
+	rec, err := json.Marshal(g.Recording())
+	if err != nil {
+		return fmt.Errorf("Save: cannot encode game: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO games (id, user_id, recording) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE
+		SET user_id = EXCLUDED.user_id, recording = EXCLUDED.recording, updated_at = CURRENT_TIMESTAMP`,
+		string(id), g.Player.ID, rec,
+	)
+	if err != nil {
+		r.logger.Error("failed to save game to db", slog.Any("err", err))
+		return fmt.Errorf("Save: cannot save game id=%v: %w", id, err)
+	}
+
+	return nil
+}
+
+func (r *gamePostgresRepo) GetByID(ctx context.Context, id domain.GameID) (*domain.Game, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var p domain.Player
+	var data []byte
+	err := r.db.QueryRowContext(ctx,
+		"SELECT u.id, u.username, g.recording FROM games g JOIN users u ON u.id = g.user_id WHERE g.id = $1",
+		string(id),
+	).Scan(&p.ID, &p.Nickname, &data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Debug("no games found", slog.String("id", string(id)))
+			return nil, domain.ErrGameNotFound
+		}
+		r.logger.Error("failed to get game from db", slog.Any("err", err))
+		return nil, fmt.Errorf("GetByID: cannot find game id=%v: %w", id, err)
+	}
+
+	var rec domain.Recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		r.logger.Error("failed to parse game", slog.Any("err", err))
+		return nil, fmt.Errorf("GetByID: cannot parse game id=%v: %w", id, err)
+	}
+
+	g, err := domain.Replay(rec, &p, domain.DefaultColorPicker())
+	if err != nil {
+		r.logger.Error("failed to restore game", slog.Any("err", err))
+		return nil, fmt.Errorf("GetByID: cannot restore game id=%v: %w", id, err)
+	}
+
+	return g, nil
+}
+
+func (r *gamePostgresRepo) Delete(ctx context.Context, id domain.GameID) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM games WHERE id = $1", string(id))
+	if err != nil {
+		r.logger.Error("failed to delete game", slog.Any("err", err))
+		return fmt.Errorf("Delete: cannot delete game id=%v: %w", id, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Delete: cannot delete game id=%v: %w", id, err)
+	}
+	if n == 0 {
+		return domain.ErrGameNotFound
+	}
+
+	return nil
+}
+
+func (r *gamePostgresRepo) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM games WHERE updated_at < $1", before)
+	if err != nil {
+		r.logger.Error("failed to delete expired games", slog.Any("err", err))
+		return 0, fmt.Errorf("DeleteExpired: cannot delete games: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("DeleteExpired: cannot delete games: %w", err)
+	}
+
+	return int(n), nil
+}
@@ -0,0 +1,125 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+var ErrNoMigrations = errors.New("no migrations are applied")
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrator applies schema migrations. It holds a single connection of the
+// pool until closed.
+type Migrator struct {
+	m      *migrate.Migrate
+	logger *slog.Logger
+}
+
+// NewMigrator reads migrations from source URL, empty source means the ones
+// built into the binary.
+func NewMigrator(logger *slog.Logger, db *sql.DB, source string) (*Migrator, error) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("NewMigrator: cannot get connection: %w", err)
+	}
+
+	// Driver made from a connection closes only the connection, the pool
+	// stays open for repositories.
+	driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{})
+	if err != nil {
+		conn.Close()
+		logger.Error("could not create migrate driver", slog.Any("err", err))
+		return nil, fmt.Errorf("NewMigrator: could not create migrate driver: %w", err)
+	}
+
+	var m *migrate.Migrate
+	if source == "" {
+		embedded, err := iofs.New(migrations, "migrations")
+		if err != nil {
+			driver.Close()
+			return nil, fmt.Errorf("NewMigrator: cannot read embedded migrations: %w", err)
+		}
+		m, err = migrate.NewWithInstance("iofs", embedded, "postgres", driver)
+	} else {
+		m, err = migrate.NewWithDatabaseInstance(source, "postgres", driver)
+	}
+	if err != nil {
+		driver.Close()
+		logger.Error("failed to setup migration", slog.Any("err", err))
+		return nil, fmt.Errorf("NewMigrator: failed to setup migration: %w", err)
+	}
+
+	return &Migrator{m: m, logger: logger}, nil
+}
+
+// Up applies all migrations which are not applied yet.
+func (mg *Migrator) Up() error {
+	if err := mg.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		mg.logger.Error("failed to apply migration", slog.Any("err", err))
+		return fmt.Errorf("Up: failed to apply migration: %w", err)
+	}
+	return nil
+}
+
+// Down reverts given number of the last applied migrations, zero or less
+// reverts all of them.
+func (mg *Migrator) Down(steps int) error {
+	var err error
+	if steps <= 0 {
+		err = mg.m.Down()
+	} else {
+		err = mg.m.Steps(-steps)
+	}
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		mg.logger.Error("failed to revert migration", slog.Any("err", err))
+		return fmt.Errorf("Down: failed to revert migration: %w", err)
+	}
+	return nil
+}
+
+// Version returns the last applied migration, dirty means it has failed
+// halfway and the schema needs a manual fix.
+func (mg *Migrator) Version() (version uint, dirty bool, err error) {
+	version, dirty, err = mg.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, ErrNoMigrations
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("Version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// Close releases the connection, the database itself stays open.
+func (mg *Migrator) Close() error {
+	srcErr, dbErr := mg.m.Close()
+	return errors.Join(srcErr, dbErr)
+}
+
+// RunMigrations applies all migrations from source, see NewMigrator.
+func RunMigrations(logger *slog.Logger, db *sql.DB, source string) error {
+	mg, err := NewMigrator(logger, db, source)
+	if err != nil {
+		return fmt.Errorf("RunMigrations: %w", err)
+	}
+	defer mg.Close()
+
+	if err := mg.Up(); err != nil {
+		return fmt.Errorf("RunMigrations: %w", err)
+	}
+
+	logger.Info("Migrations ran successfully.")
+	return nil
+}
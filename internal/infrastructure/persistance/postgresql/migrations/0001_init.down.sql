@@ -1,2 +1,2 @@
-DROP TABLE users IF EXISTS 
-DROP TABLE records IF EXISTS;
+DROP TABLE IF EXISTS records;
+DROP TABLE IF EXISTS users;
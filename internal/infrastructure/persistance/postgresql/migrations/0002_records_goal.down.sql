@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS records_board_idx;
+
+ALTER TABLE records DROP COLUMN IF EXISTS hints;
+ALTER TABLE records DROP COLUMN IF EXISTS goal;
@@ -0,0 +1,4 @@
+ALTER TABLE records ADD COLUMN IF NOT EXISTS goal TEXT NOT NULL DEFAULT 'any';
+ALTER TABLE records ADD COLUMN IF NOT EXISTS hints INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS records_board_idx ON records (pegs, disks, steps);
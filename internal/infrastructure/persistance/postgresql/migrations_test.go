@@ -0,0 +1,29 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	src, err := iofs.New(migrations, "migrations")
+	require.NoError(t, err)
+	defer src.Close()
+
+	var versions []uint
+	for version, err := src.First(); err == nil; version, err = src.Next(version) {
+		versions = append(versions, version)
+
+		up, _, err := src.ReadUp(version)
+		require.NoError(t, err, "up migration %d", version)
+		up.Close()
+		down, _, err := src.ReadDown(version)
+		require.NoError(t, err, "down migration %d", version)
+		down.Close()
+	}
+
+	assert.Equal(t, []uint{1, 2, 3, 4}, versions)
+}
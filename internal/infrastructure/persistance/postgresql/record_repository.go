@@ -0,0 +1,84 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/domain"
+)
+
+// defaultRecordsLimit is used when filter has no limit.
+const defaultRecordsLimit = 10
+
+type recordPostgresRepo struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewRecordPostgresRepo(logger *slog.Logger, db *sql.DB) *recordPostgresRepo {
+	return &recordPostgresRepo{db: db, logger: logger}
+}
+
+func (r *recordPostgresRepo) Save(ctx context.Context, rec *domain.Record) (domain.RecordID, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var id int
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO records (user_id, steps, pegs, disks, goal, ruleset, hints, achieved_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
+		rec.PlayerID, rec.Steps, rec.Pegs, rec.Disks, rec.Goal, rec.Ruleset, rec.HintsUsed, rec.AchievedAt,
+	).Scan(&id)
+	if err != nil {
+		r.logger.Error("failed to save record to db", slog.Any("err", err))
+		return 0, fmt.Errorf("Save: cannot save record: %w", err)
+	}
+
+	return domain.RecordID(id), nil
+}
+
+func (r *recordPostgresRepo) GetTop(ctx context.Context, filter domain.RecordFilter) ([]*domain.Record, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultRecordsLimit
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT r.id, r.user_id, u.username, r.steps, r.pegs, r.disks, r.goal, r.ruleset, r.hints, r.achieved_at
+		FROM records r JOIN users u ON u.id = r.user_id
+		WHERE ($1 = 0 OR r.pegs = $1) AND ($2 = 0 OR r.disks = $2) AND ($3 = '' OR r.ruleset = $3)
+			AND ($4 = '' OR r.goal = $4)
+		ORDER BY r.steps, r.hints, r.achieved_at
+		LIMIT $5`,
+		filter.Pegs, filter.Disks, filter.Ruleset, filter.Goal, limit,
+	)
+	if err != nil {
+		r.logger.Error("failed to get records", slog.Any("err", err))
+		return nil, fmt.Errorf("GetTop: cannot get records: %w", err)
+	}
+	defer rows.Close()
+
+	records := make([]*domain.Record, 0)
+	for rows.Next() {
+		var rec domain.Record
+		err := rows.Scan(&rec.ID, &rec.PlayerID, &rec.Nickname, &rec.Steps, &rec.Pegs, &rec.Disks, &rec.Goal, &rec.Ruleset, &rec.HintsUsed, &rec.AchievedAt)
+		if err != nil {
+			r.logger.Error("failed to parse records", slog.Any("err", err))
+			return nil, fmt.Errorf("GetTop: cannot parse records: %w", err)
+		}
+		records = append(records, &rec)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("failed to read records", slog.Any("err", err))
+		return nil, fmt.Errorf("GetTop: cannot read records: %w", err)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,69 @@
+package postgresql
+
+import (
+	"database/sql"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/config"
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/infrastructure/persistance/repotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDB opens a database which may be wiped, its DSN is taken from
+// HANOI_TEST_DSN. Tests are skipped without it.
+func testDB(t *testing.T) (*slog.Logger, *sql.DB) {
+	dsn := os.Getenv("HANOI_TEST_DSN")
+	if dsn == "" {
+		t.Skip("HANOI_TEST_DSN is not set")
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := config.Default().Database
+	cfg.DSN = dsn
+
+	db, err := Open(logger, cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return logger, db
+}
+
+func TestContract(t *testing.T) {
+	logger, db := testDB(t)
+	require.NoError(t, RunMigrations(logger, db, ""))
+
+	players, err := NewPlayerPostgresRepo(logger, db)
+	require.NoError(t, err)
+
+	repotest.Run(t, func(t *testing.T) repotest.Repos {
+		_, err := db.Exec("TRUNCATE games, records, users RESTART IDENTITY")
+		require.NoError(t, err)
+
+		return repotest.Repos{
+			Players: players,
+			Records: NewRecordPostgresRepo(logger, db),
+			Games:   NewGamePostgresRepo(logger, db),
+		}
+	})
+}
+
+func TestMigrationsDownAndUp(t *testing.T) {
+	logger, db := testDB(t)
+	mg, err := NewMigrator(logger, db, "")
+	require.NoError(t, err)
+	defer mg.Close()
+
+	require.NoError(t, mg.Up())
+	require.NoError(t, mg.Down(0))
+	_, _, err = mg.Version()
+	assert.ErrorIs(t, err, ErrNoMigrations)
+
+	require.NoError(t, mg.Up())
+	version, dirty, err := mg.Version()
+	require.NoError(t, err)
+	assert.False(t, dirty)
+	assert.Equal(t, uint(4), version)
+}
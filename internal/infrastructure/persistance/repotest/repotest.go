@@ -0,0 +1,261 @@
+// Package repotest is the contract every storage backend has to pass. Each
+// backend runs Run from its own tests.
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Repos are repositories sharing one empty storage.
+type Repos struct {
+	Players domain.PlayerRepository
+	Records domain.RecordRepository
+	Games   domain.GameRepository
+}
+
+// Run checks repositories made by open, it is called for every subtest and
+// has to return an empty storage.
+func Run(t *testing.T, open func(t *testing.T) Repos) {
+	t.Run("players", func(t *testing.T) { testPlayers(t, open(t)) })
+	t.Run("duplicate player", func(t *testing.T) { testDuplicatePlayer(t, open(t)) })
+	t.Run("records order", func(t *testing.T) { testRecordsOrder(t, open(t)) })
+	t.Run("records filter", func(t *testing.T) { testRecordsFilter(t, open(t)) })
+	t.Run("games", func(t *testing.T) { testGames(t, open(t)) })
+	t.Run("expired games", func(t *testing.T) { testExpiredGames(t, open(t)) })
+}
+
+func newPlayer(t *testing.T, r Repos, nickname string) *domain.Player {
+	t.Helper()
+
+	id, err := r.Players.Save(context.Background(), nickname)
+	require.NoError(t, err)
+	return &domain.Player{ID: id, Nickname: nickname}
+}
+
+func testPlayers(t *testing.T, r Repos) {
+	ctx := context.Background()
+
+	all, err := r.Players.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, all)
+
+	anru := newPlayer(t, r, "anru")
+	kitakaze := newPlayer(t, r, "kitakaze")
+	assert.NotEqual(t, anru.ID, kitakaze.ID)
+
+	p, err := r.Players.GetByID(ctx, anru.ID)
+	require.NoError(t, err)
+	assert.Equal(t, anru, p)
+
+	p, err = r.Players.GetByNickname(ctx, "kitakaze")
+	require.NoError(t, err)
+	assert.Equal(t, kitakaze, p)
+
+	all, err = r.Players.GetAll(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*domain.Player{anru, kitakaze}, all)
+
+	_, err = r.Players.GetByID(ctx, kitakaze.ID+100)
+	assert.ErrorIs(t, err, domain.ErrPlayerNotFound)
+	_, err = r.Players.GetByNickname(ctx, "nobody")
+	assert.ErrorIs(t, err, domain.ErrPlayerNotFound)
+}
+
+func testDuplicatePlayer(t *testing.T, r Repos) {
+	anru := newPlayer(t, r, "anru")
+
+	_, err := r.Players.Save(context.Background(), "anru")
+	var cannotCreate *domain.ErrCannotCreatePlayer
+	assert.ErrorAs(t, err, &cannotCreate)
+
+	p, err := r.Players.GetByNickname(context.Background(), "anru")
+	require.NoError(t, err)
+	assert.Equal(t, anru, p, "first player is kept")
+}
+
+func newRecord(p *domain.Player, steps uint, hints uint, at time.Time) *domain.Record {
+	return &domain.Record{
+		PlayerID:   p.ID,
+		Nickname:   p.Nickname,
+		Steps:      steps,
+		Pegs:       3,
+		Disks:      3,
+		Goal:       "any",
+		Ruleset:    domain.StandardRulesetName,
+		HintsUsed:  hints,
+		AchievedAt: at,
+	}
+}
+
+func testRecordsOrder(t *testing.T, r Repos) {
+	ctx := context.Background()
+	anru := newPlayer(t, r, "anru")
+	kitakaze := newPlayer(t, r, "kitakaze")
+	start := time.Now().Truncate(time.Millisecond)
+
+	// saved in the wrong order on purpose
+	records := []*domain.Record{
+		newRecord(anru, 9, 0, start),
+		newRecord(kitakaze, 7, 1, start),
+		newRecord(anru, 7, 0, start.Add(time.Second)),
+		newRecord(kitakaze, 7, 0, start),
+	}
+	for _, rec := range records {
+		id, err := r.Records.Save(ctx, rec)
+		require.NoError(t, err)
+		rec.ID = id
+	}
+
+	top, err := r.Records.GetTop(ctx, domain.RecordFilter{})
+	require.NoError(t, err)
+	require.Len(t, top, 4)
+
+	want := []*domain.Record{records[3], records[2], records[1], records[0]}
+	for i := range want {
+		assert.Equal(t, want[i].ID, top[i].ID, "#%d", i)
+		assert.Equal(t, want[i].Nickname, top[i].Nickname, "#%d", i)
+		assert.Equal(t, want[i].Steps, top[i].Steps, "#%d", i)
+		assert.Equal(t, want[i].HintsUsed, top[i].HintsUsed, "#%d", i)
+		assert.Equal(t, want[i].Goal, top[i].Goal, "#%d", i)
+		assert.Equal(t, want[i].Ruleset, top[i].Ruleset, "#%d", i)
+		assert.WithinDuration(t, want[i].AchievedAt, top[i].AchievedAt, time.Millisecond, "#%d", i)
+	}
+}
+
+func testRecordsFilter(t *testing.T, r Repos) {
+	ctx := context.Background()
+	anru := newPlayer(t, r, "anru")
+	now := time.Now()
+
+	for i := range 12 {
+		rec := newRecord(anru, uint(10+i), 0, now)
+		rec.Pegs, rec.Disks = 3+i%2, 3+i%3
+		if i%4 == 0 {
+			rec.Ruleset = "other"
+		}
+		if i%3 == 2 {
+			rec.Goal = "peg:2"
+		}
+		_, err := r.Records.Save(ctx, rec)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		filter domain.RecordFilter
+		want   int
+	}{
+		{domain.RecordFilter{}, 10},
+		{domain.RecordFilter{Limit: 20}, 12},
+		{domain.RecordFilter{Limit: 3}, 3},
+		{domain.RecordFilter{Pegs: 4}, 6},
+		{domain.RecordFilter{Disks: 5}, 4},
+		{domain.RecordFilter{Pegs: 3, Disks: 3}, 2},
+		{domain.RecordFilter{Pegs: 7}, 0},
+		{domain.RecordFilter{Ruleset: domain.StandardRulesetName, Limit: 20}, 9},
+		{domain.RecordFilter{Ruleset: "other"}, 3},
+		{domain.RecordFilter{Ruleset: "other", Pegs: 3}, 3},
+		{domain.RecordFilter{Ruleset: "other", Pegs: 4}, 0},
+		{domain.RecordFilter{Goal: "any", Limit: 20}, 8},
+		{domain.RecordFilter{Goal: "peg:2"}, 4},
+		{domain.RecordFilter{Goal: "peg:2", Ruleset: "other"}, 1},
+		{domain.RecordFilter{Goal: "peg:1"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%+v", tt.filter), func(t *testing.T) {
+			top, err := r.Records.GetTop(ctx, tt.filter)
+			require.NoError(t, err)
+			assert.Len(t, top, tt.want)
+			for _, rec := range top {
+				if tt.filter.Pegs != 0 {
+					assert.Equal(t, tt.filter.Pegs, rec.Pegs)
+				}
+				if tt.filter.Disks != 0 {
+					assert.Equal(t, tt.filter.Disks, rec.Disks)
+				}
+				if tt.filter.Ruleset != "" {
+					assert.Equal(t, tt.filter.Ruleset, rec.Ruleset)
+				}
+				if tt.filter.Goal != "" {
+					assert.Equal(t, tt.filter.Goal, rec.Goal)
+				}
+			}
+		})
+	}
+}
+
+func newGame(t *testing.T, p *domain.Player) *domain.Game {
+	t.Helper()
+
+	g, err := domain.NewGame(3, 4, p, domain.DefaultColorPicker(), domain.WithSeed(42), domain.WithGoal(domain.PegGoal(2)))
+	require.NoError(t, err)
+	return g
+}
+
+func testGames(t *testing.T, r Repos) {
+	ctx := context.Background()
+	anru := newPlayer(t, r, "anru")
+	id := domain.NewGameID()
+
+	_, err := r.Games.GetByID(ctx, id)
+	assert.ErrorIs(t, err, domain.ErrGameNotFound)
+
+	g := newGame(t, anru)
+	require.NoError(t, r.Games.Save(ctx, id, g))
+
+	// change the game, so the update is visible
+	moves, err := domain.Solve(g)
+	require.NoError(t, err)
+	require.NotEmpty(t, moves)
+	require.NoError(t, g.MoveDisk(moves[0].From, moves[0].To))
+	g.HintsUsed = 2
+	require.NoError(t, r.Games.Save(ctx, id, g))
+
+	got, err := r.Games.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, anru, got.Player)
+	assert.Equal(t, g.Layout(), got.Layout())
+	assert.Equal(t, g.InitialLayout(), got.InitialLayout())
+	assert.Equal(t, g.Seed, got.Seed)
+	assert.Equal(t, g.Goal, got.Goal)
+	assert.Equal(t, g.Rules, got.Rules)
+	assert.Equal(t, g.Step, got.Step)
+	assert.Equal(t, g.HintsUsed, got.HintsUsed)
+
+	require.NoError(t, r.Games.Delete(ctx, id))
+	_, err = r.Games.GetByID(ctx, id)
+	assert.ErrorIs(t, err, domain.ErrGameNotFound)
+	assert.ErrorIs(t, r.Games.Delete(ctx, id), domain.ErrGameNotFound)
+
+	g.Player = nil
+	assert.ErrorIs(t, r.Games.Save(ctx, id, g), domain.ErrPlayerCannotBeNil)
+}
+
+func testExpiredGames(t *testing.T, r Repos) {
+	ctx := context.Background()
+	anru := newPlayer(t, r, "anru")
+
+	ids := []domain.GameID{domain.NewGameID(), domain.NewGameID()}
+	for _, id := range ids {
+		require.NoError(t, r.Games.Save(ctx, id, newGame(t, anru)))
+	}
+
+	n, err := r.Games.DeleteExpired(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "fresh games are kept")
+
+	n, err = r.Games.DeleteExpired(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	for _, id := range ids {
+		_, err := r.Games.GetByID(ctx, id)
+		assert.ErrorIs(t, err, domain.ErrGameNotFound)
+	}
+}
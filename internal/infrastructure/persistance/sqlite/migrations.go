@@ -0,0 +1,116 @@
+package sqlite
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+var ErrNoMigrations = errors.New("no migrations are applied")
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrator applies schema migrations.
+type Migrator struct {
+	m      *migrate.Migrate
+	src    source.Driver
+	logger *slog.Logger
+}
+
+// NewMigrator reads migrations from source URL, empty source means the ones
+// built into the binary.
+func NewMigrator(logger *slog.Logger, db *sql.DB, sourceURL string) (*Migrator, error) {
+	driver, err := sqlite.WithInstance(db, &sqlite.Config{})
+	if err != nil {
+		logger.Error("could not create migrate driver", slog.Any("err", err))
+		return nil, fmt.Errorf("NewMigrator: could not create migrate driver: %w", err)
+	}
+
+	var src source.Driver
+	if sourceURL == "" {
+		src, err = iofs.New(migrations, "migrations")
+	} else {
+		src, err = source.Open(sourceURL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("NewMigrator: cannot read migrations: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("migrations", src, "sqlite", driver)
+	if err != nil {
+		src.Close()
+		logger.Error("failed to setup migration", slog.Any("err", err))
+		return nil, fmt.Errorf("NewMigrator: failed to setup migration: %w", err)
+	}
+
+	return &Migrator{m: m, src: src, logger: logger}, nil
+}
+
+// Up applies all migrations which are not applied yet.
+func (mg *Migrator) Up() error {
+	if err := mg.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		mg.logger.Error("failed to apply migration", slog.Any("err", err))
+		return fmt.Errorf("Up: failed to apply migration: %w", err)
+	}
+	return nil
+}
+
+// Down reverts given number of the last applied migrations, zero or less
+// reverts all of them.
+func (mg *Migrator) Down(steps int) error {
+	var err error
+	if steps <= 0 {
+		err = mg.m.Down()
+	} else {
+		err = mg.m.Steps(-steps)
+	}
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		mg.logger.Error("failed to revert migration", slog.Any("err", err))
+		return fmt.Errorf("Down: failed to revert migration: %w", err)
+	}
+	return nil
+}
+
+// Version returns the last applied migration, dirty means it has failed
+// halfway and the schema needs a manual fix.
+func (mg *Migrator) Version() (version uint, dirty bool, err error) {
+	version, dirty, err = mg.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, ErrNoMigrations
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("Version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// Close releases migrations source. Migrate driver of SQLite closes the
+// whole database, so it is left to the caller.
+func (mg *Migrator) Close() error {
+	return mg.src.Close()
+}
+
+// RunMigrations applies all migrations from source, see NewMigrator.
+func RunMigrations(logger *slog.Logger, db *sql.DB, source string) error {
+	mg, err := NewMigrator(logger, db, source)
+	if err != nil {
+		return fmt.Errorf("RunMigrations: %w", err)
+	}
+	defer mg.Close()
+
+	if err := mg.Up(); err != nil {
+		return fmt.Errorf("RunMigrations: %w", err)
+	}
+
+	logger.Info("Migrations ran successfully.")
+	return nil
+}
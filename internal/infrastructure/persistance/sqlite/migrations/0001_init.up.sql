@@ -0,0 +1,29 @@
+-- Times are unix microseconds, SQLite has no time type and text ones do
+-- not compare well.
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username TEXT NOT NULL UNIQUE,
+    created_at INTEGER NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000 AS INTEGER))
+);
+
+CREATE TABLE IF NOT EXISTS records (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    steps INTEGER NOT NULL,
+    pegs INTEGER NOT NULL,
+    disks INTEGER NOT NULL,
+    goal TEXT NOT NULL DEFAULT 'any',
+    hints INTEGER NOT NULL DEFAULT 0,
+    achieved_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS records_board_idx ON records (pegs, disks, steps);
+
+CREATE TABLE IF NOT EXISTS games (
+    id TEXT PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recording TEXT NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS games_updated_at_idx ON games (updated_at);
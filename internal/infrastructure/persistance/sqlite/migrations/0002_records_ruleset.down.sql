@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS records_ruleset_idx;
+
+ALTER TABLE records DROP COLUMN ruleset;
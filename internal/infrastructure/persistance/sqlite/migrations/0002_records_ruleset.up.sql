@@ -0,0 +1,3 @@
+ALTER TABLE records ADD COLUMN ruleset TEXT NOT NULL DEFAULT 'standard';
+
+CREATE INDEX IF NOT EXISTS records_ruleset_idx ON records (ruleset, pegs, disks, steps);
@@ -0,0 +1,140 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/config"
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/domain"
+	_ "modernc.org/sqlite"
+)
+
+const DriverName = "sqlite"
+
+// Open opens the database file, it is created when missing. Foreign keys
+// are on and writers wait for each other instead of failing with "database
+// is locked".
+func Open(logger *slog.Logger, cfg config.Database) (*sql.DB, error) {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", cfg.ConnectTimeout.Milliseconds()))
+	params.Add("_pragma", "journal_mode(WAL)")
+
+	db, err := sql.Open(DriverName, "file:"+cfg.SQLitePath+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("Open: cannot open db driver: %w", err)
+	}
+
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectTimeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		logger.Error("unable to open database", slog.Any("err", err))
+		return nil, fmt.Errorf("Open: unable to open database %s: %w", cfg.SQLitePath, err)
+	}
+
+	return db, nil
+}
+
+type playerSqliteRepo struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewPlayerSqliteRepo(logger *slog.Logger, db *sql.DB) *playerSqliteRepo {
+	return &playerSqliteRepo{db: db, logger: logger}
+}
+
+func (r *playerSqliteRepo) Save(ctx context.Context, nickname string) (domain.PlayerID, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if utf8.RuneCountInString(nickname) > 100 {
+		r.logger.Error("will not save player to db: name is too long", slog.String("nickname", nickname))
+		return 0, &domain.ErrCannotCreatePlayer{Nickname: nickname, Reason: "name is too long"}
+	}
+
+	var id int
+	err := r.db.QueryRowContext(ctx, "INSERT INTO users (username) VALUES (?) RETURNING id", nickname).Scan(&id)
+	if err != nil {
+		r.logger.Error("failed to save player to db", slog.Any("err", err))
+		return 0, &domain.ErrCannotCreatePlayer{Nickname: nickname, Reason: err.Error()}
+	}
+
+	return domain.PlayerID(id), nil
+}
+
+func (r *playerSqliteRepo) GetByID(ctx context.Context, id domain.PlayerID) (*domain.Player, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var p domain.Player
+	err := r.db.QueryRowContext(ctx, "SELECT id, username FROM users WHERE id = ?", id).Scan(&p.ID, &p.Nickname)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Debug("no players found", slog.Int("id", int(id)))
+			return nil, domain.ErrPlayerNotFound
+		}
+		r.logger.Error("failed to get players from db", slog.Any("err", err))
+		return nil, fmt.Errorf("GetByID: cannot find user id=%v: %w", id, err)
+	}
+
+	return &p, nil
+}
+
+func (r *playerSqliteRepo) GetAll(ctx context.Context) ([]*domain.Player, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT id, username FROM users ORDER BY id")
+	if err != nil {
+		r.logger.Error("failed to get users", slog.Any("err", err))
+		return nil, fmt.Errorf("GetAll: cannot get users: %w", err)
+	}
+	defer rows.Close()
+
+	players := make([]*domain.Player, 0)
+	for rows.Next() {
+		var p domain.Player
+		if err := rows.Scan(&p.ID, &p.Nickname); err != nil {
+			r.logger.Error("failed to parse users", slog.Any("err", err))
+			return nil, fmt.Errorf("GetAll: cannot parse users: %w", err)
+		}
+		players = append(players, &p)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("failed to read users", slog.Any("err", err))
+		return nil, fmt.Errorf("GetAll: cannot read users: %w", err)
+	}
+
+	return players, nil
+}
+
+func (r *playerSqliteRepo) GetByNickname(ctx context.Context, nickname string) (*domain.Player, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var p domain.Player
+	err := r.db.QueryRowContext(ctx, "SELECT id, username FROM users WHERE username = ?", nickname).Scan(&p.ID, &p.Nickname)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Debug("no players found", slog.String("nickname", nickname))
+			return nil, domain.ErrPlayerNotFound
+		}
+		r.logger.Error("failed to get players from db", slog.Any("err", err))
+		return nil, fmt.Errorf("GetByNickname: cannot find user nickname=%v: %w", nickname, err)
+	}
+
+	return &p, nil
+}
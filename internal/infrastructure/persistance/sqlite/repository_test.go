@@ -0,0 +1,59 @@
+package sqlite
+
+import (
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/config"
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/infrastructure/persistance/repotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContract(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	repotest.Run(t, func(t *testing.T) repotest.Repos {
+		cfg := config.Default().Database
+		cfg.SQLitePath = filepath.Join(t.TempDir(), "hanoi.db")
+
+		db, err := Open(logger, cfg)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		require.NoError(t, RunMigrations(logger, db, ""))
+
+		return repotest.Repos{
+			Players: NewPlayerSqliteRepo(logger, db),
+			Records: NewRecordSqliteRepo(logger, db),
+			Games:   NewGameSqliteRepo(logger, db),
+		}
+	})
+}
+
+func TestMigrateDown(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := config.Default().Database
+	cfg.SQLitePath = filepath.Join(t.TempDir(), "hanoi.db")
+
+	db, err := Open(logger, cfg)
+	require.NoError(t, err)
+	defer db.Close()
+
+	mg, err := NewMigrator(logger, db, "")
+	require.NoError(t, err)
+	defer mg.Close()
+
+	_, _, err = mg.Version()
+	require.ErrorIs(t, err, ErrNoMigrations)
+
+	require.NoError(t, mg.Up())
+	version, dirty, err := mg.Version()
+	require.NoError(t, err)
+	require.Equal(t, uint(2), version)
+	require.False(t, dirty)
+
+	require.NoError(t, mg.Down(0))
+	_, _, err = mg.Version()
+	require.ErrorIs(t, err, ErrNoMigrations)
+}
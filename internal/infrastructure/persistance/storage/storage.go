@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/config"
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/domain"
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/infrastructure/persistance/inmemory"
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/infrastructure/persistance/postgresql"
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/infrastructure/persistance/sqlite"
+)
+
+// Storage is the set of repositories chosen by config.
+type Storage struct {
+	Players domain.PlayerRepository
+	Records domain.RecordRepository
+	Games   domain.GameRepository
+	close   func() error
+}
+
+// Open connects repositories of the kind set in cfg.Storage. Memory storage
+// lives as long as the process, nothing is saved between runs.
+func Open(logger *slog.Logger, cfg *config.Config) (*Storage, error) {
+	switch cfg.Storage {
+	case config.StorageMemory:
+		return &Storage{
+			Players: inmemory.NewPlayerInmemoryRepo(logger),
+			Records: inmemory.NewRecordInmemoryRepo(logger),
+			Games:   inmemory.NewGameInmemoryRepo(logger),
+			close:   func() error { return nil },
+		}, nil
+	case config.StoragePostgres:
+		db, err := postgresql.Open(logger, cfg.Database)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := postgresql.RunMigrations(logger, db, cfg.Database.Migrations); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to apply migrations: %w", err)
+		}
+
+		players, err := postgresql.NewPlayerPostgresRepo(logger, db)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("cannot create player repo: %w", err)
+		}
+
+		return &Storage{
+			Players: players,
+			Records: postgresql.NewRecordPostgresRepo(logger, db),
+			Games:   postgresql.NewGamePostgresRepo(logger, db),
+			close:   db.Close,
+		}, nil
+	case config.StorageSQLite:
+		db, err := sqlite.Open(logger, cfg.Database)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := sqlite.RunMigrations(logger, db, cfg.Database.Migrations); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to apply migrations: %w", err)
+		}
+
+		return &Storage{
+			Players: sqlite.NewPlayerSqliteRepo(logger, db),
+			Records: sqlite.NewRecordSqliteRepo(logger, db),
+			Games:   sqlite.NewGameSqliteRepo(logger, db),
+			close:   db.Close,
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown storage %q", config.ErrInvalidConfig, cfg.Storage)
+	}
+}
+
+func (s *Storage) Close() error {
+	return s.close()
+}
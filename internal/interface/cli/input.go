@@ -0,0 +1,61 @@
+package cli
+
+import "io"
+
+// Input hands out what is read from the terminal to whoever reads next.
+// Reads are made on demand in a goroutine, so the full-screen mode can wait
+// for keys and the clock at once. A read it leaves running when it stops is
+// not lost, its bytes go to the next reader, the line mode usually.
+//
+// Input is not safe for concurrent use, readers take turns.
+type Input struct {
+	r    io.Reader
+	read chan inputChunk
+	// reading is set while a read is running, its chunk comes to read
+	reading bool
+	rest    []byte
+	err     error
+}
+
+type inputChunk struct {
+	data []byte
+	err  error
+}
+
+func NewInput(r io.Reader) *Input {
+	return &Input{r: r, read: make(chan inputChunk, 1)}
+}
+
+// Read implements io.Reader, bytes left by the previous reader come first.
+func (in *Input) Read(p []byte) (int, error) {
+	if len(in.rest) == 0 && in.err == nil {
+		in.take(<-in.next())
+	}
+	if len(in.rest) == 0 {
+		return 0, in.err
+	}
+
+	n := copy(p, in.rest)
+	in.rest = in.rest[n:]
+	return n, nil
+}
+
+// next starts a read unless one is running already and returns the channel
+// it comes to. The chunk received should be passed to take.
+func (in *Input) next() <-chan inputChunk {
+	if !in.reading {
+		in.reading = true
+		go func() {
+			buf := make([]byte, 256)
+			n, err := in.r.Read(buf)
+			in.read <- inputChunk{data: buf[:n], err: err}
+		}()
+	}
+	return in.read
+}
+
+func (in *Input) take(c inputChunk) {
+	in.reading = false
+	in.rest = append(in.rest, c.data...)
+	in.err = c.err
+}
@@ -0,0 +1,279 @@
+package cli
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/domain"
+)
+
+// ColorMode is how many colors the terminal can show.
+type ColorMode int
+
+const (
+	// ColorNone draws disks with plain characters, for dumb terminals and
+	// NO_COLOR users
+	ColorNone ColorMode = iota
+	// Color16 uses the basic 8 background colors
+	Color16
+	// Color256 uses the xterm 6x6x6 color cube
+	Color256
+	// ColorTrue uses 24-bit colors
+	ColorTrue
+)
+
+const (
+	ansiReset = "\033[0m"
+	ansiBlack = "\033[30m"
+)
+
+// DetectColorMode guesses terminal capabilities from environment variables.
+// getenv is usually os.Getenv.
+func DetectColorMode(getenv func(string) string) ColorMode {
+	if getenv("NO_COLOR") != "" {
+		return ColorNone
+	}
+
+	term := getenv("TERM")
+	if term == "" || term == "dumb" {
+		return ColorNone
+	}
+
+	switch getenv("COLORTERM") {
+	case "truecolor", "24bit":
+		return ColorTrue
+	}
+
+	if strings.Contains(term, "256color") {
+		return Color256
+	}
+
+	return Color16
+}
+
+// background returns escape sequence which paints background with c.
+func (m ColorMode) background(c color.Color) string {
+	if c == nil || m == ColorNone {
+		return ""
+	}
+
+	r, g, b := domain.RGB(c)
+	switch m {
+	case ColorTrue:
+		return fmt.Sprintf("\033[48;2;%d;%d;%dm", r, g, b)
+	case Color256:
+		cube := func(v uint8) int { return (int(v)*5 + 127) / 255 }
+		return fmt.Sprintf("\033[48;5;%dm", 16+36*cube(r)+6*cube(g)+cube(b))
+	default:
+		bit := func(v uint8, n int) int {
+			if v > 127 {
+				return 1 << n
+			}
+			return 0
+		}
+		return fmt.Sprintf("\033[%dm", 40+bit(r, 0)+bit(g, 1)+bit(b, 2))
+	}
+}
+
+// Renderer draws the board as centered horizontal bars, one column per peg.
+type Renderer struct {
+	Mode ColorMode
+	// Width of the terminal in columns, 0 means unlimited. Bars are scaled
+	// down to fit it, and when even that is not enough pegs are wrapped
+	// into several rows.
+	Width int
+}
+
+func NewRenderer(mode ColorMode, width int) *Renderer {
+	return &Renderer{Mode: mode, Width: width}
+}
+
+// columnGap is the number of spaces between peg columns.
+const columnGap = 1
+
+// layout decides how wide a peg column is and how many pegs fit in a row.
+func (r *Renderer) layout(pegs int, disks int) (width int, perRow int) {
+	natural := 2*disks + 1
+	if r.Width <= 0 || pegs*natural+(pegs-1)*columnGap <= r.Width {
+		return natural, pegs
+	}
+
+	width = (r.Width - (pegs-1)*columnGap) / pegs
+	if width%2 == 0 {
+		width--
+	}
+
+	// Too narrow bars are hard to tell apart, better wrap.
+	if minWidth := min(natural, 7); width < minWidth {
+		width = minWidth
+		perRow = max(1, (r.Width+columnGap)/(width+columnGap))
+		return width, perRow
+	}
+
+	return width, pegs
+}
+
+// barWidth is odd, so bars are centered exactly on the peg.
+func barWidth(size uint, disks int, column int) int {
+	half := (column - 1) / 2
+	w := (int(size)*half + disks - 1) / disks
+	return 2*max(w, 1) + 1
+}
+
+// Highlight marks pegs chosen in interactive mode, -1 means none.
+type Highlight struct {
+	// Cursor is the peg under cursor, its label is marked
+	Cursor int
+	// Held is the peg whose top disk is picked up. The disk is drawn lifted
+	// above the cursor peg.
+	Held int
+}
+
+var noHighlight = Highlight{Cursor: -1, Held: -1}
+
+// Render draws the game into w.
+func (r *Renderer) Render(w io.Writer, g *domain.Game) error {
+	return r.RenderHighlighted(w, g, noHighlight)
+}
+
+// RenderHighlighted draws the game with cursor and picked up disk.
+func (r *Renderer) RenderHighlighted(w io.Writer, g *domain.Game, h Highlight) error {
+	// disks of every peg from bottom to top, sizes may repeat in some
+	// rulesets so bars are scaled by the biggest one
+	pegs := make([][]*domain.Disk, len(g.Pegs))
+	biggest := 1
+	for i, p := range g.Pegs {
+		for d := p.TopDisk; d != nil; d = d.Next {
+			pegs[i] = append([]*domain.Disk{d}, pegs[i]...)
+			biggest = max(biggest, int(d.Size))
+		}
+	}
+
+	column, perRow := r.layout(len(g.Pegs), biggest)
+
+	var held *domain.Disk
+	if h.Held >= 0 && h.Held < len(pegs) && len(pegs[h.Held]) > 0 {
+		held = pegs[h.Held][len(pegs[h.Held])-1]
+		pegs[h.Held] = pegs[h.Held][:len(pegs[h.Held])-1]
+	}
+
+	var b, line strings.Builder
+	endLine := func() {
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteString("\n")
+		line.Reset()
+	}
+
+	for first := 0; first < len(pegs); first += perRow {
+		last := min(first+perRow, len(pegs))
+		if first > 0 {
+			endLine()
+		}
+
+		if held != nil {
+			for i := first; i < last; i++ {
+				if i > first {
+					line.WriteString(strings.Repeat(" ", columnGap))
+				}
+				if i == h.Cursor {
+					r.writeDisk(&line, held, biggest, column)
+				} else {
+					line.WriteString(strings.Repeat(" ", column))
+				}
+			}
+			endLine()
+		}
+
+		for level := g.TotalDisks - 1; level >= 0; level-- {
+			for i := first; i < last; i++ {
+				if i > first {
+					line.WriteString(strings.Repeat(" ", columnGap))
+				}
+				if level < len(pegs[i]) {
+					r.writeDisk(&line, pegs[i][level], biggest, column)
+				} else {
+					line.WriteString(center("|", column))
+				}
+			}
+			endLine()
+		}
+
+		for i := first; i < last; i++ {
+			if i > first {
+				line.WriteString(strings.Repeat(" ", columnGap))
+			}
+			line.WriteString(center(pegLabel(i, column, i == h.Cursor), column))
+		}
+		endLine()
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// pegLabel is the longest label which fits the column, cursor peg label is
+// put in brackets.
+func pegLabel(i int, column int, cursor bool) string {
+	for _, label := range []string{"Peg #" + strconv.Itoa(i), "#" + strconv.Itoa(i)} {
+		if cursor {
+			label = ">" + label + "<"
+		}
+		if len(label) <= column {
+			return label
+		}
+	}
+	if cursor {
+		return "^"
+	}
+	return strconv.Itoa(i)
+}
+
+func (r *Renderer) writeDisk(b *strings.Builder, d *domain.Disk, disks int, column int) {
+	width := barWidth(d.Size, disks, column)
+	pad := (column - width) / 2
+
+	plain := r.Mode == ColorNone || d.Color == nil
+	room := width
+	if plain {
+		room = width - 2
+	}
+
+	// magnetic disks tell the pole facing up, narrow ones only the pole
+	label := strconv.FormatUint(uint64(d.Size), 10) + d.Up.String()
+	if len(label) > room {
+		label = d.Up.String()
+	}
+
+	var bar string
+	if plain {
+		bar = strings.Repeat("=", width)
+		if len(label) <= width-2 {
+			bar = center(label, width)
+			bar = strings.ReplaceAll(bar, " ", "=")
+		}
+		if width >= 3 {
+			bar = "<" + bar[1:width-1] + ">"
+		}
+	} else {
+		if len(label) > width {
+			label = ""
+		}
+		bar = r.Mode.background(d.Color) + ansiBlack + center(label, width) + ansiReset
+	}
+
+	b.WriteString(strings.Repeat(" ", pad))
+	b.WriteString(bar)
+	b.WriteString(strings.Repeat(" ", column-width-pad))
+}
+
+// center pads s with spaces to width, s is expected to fit.
+func center(s string, width int) string {
+	if len(s) >= width {
+		return s
+	}
+	left := (width - len(s)) / 2
+	return strings.Repeat(" ", left) + s + strings.Repeat(" ", width-len(s)-left)
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGame(t *testing.T, pegs uint, disks uint, opts ...domain.GameOption) *domain.Game {
+	t.Helper()
+
+	g, err := domain.NewGame(pegs, disks, &domain.Player{}, domain.DefaultColorPicker(), opts...)
+	require.NoError(t, err)
+	return g
+}
+
+func render(t *testing.T, r *Renderer, g *domain.Game) string {
+	t.Helper()
+
+	var b bytes.Buffer
+	require.NoError(t, r.Render(&b, g))
+	return b.String()
+}
+
+func TestRenderPlain(t *testing.T) {
+	g := newGame(t, 3, 3, domain.WithStartLayout(domain.ClassicStart))
+	require.NoError(t, g.MoveDisk(0, 2))
+
+	want := "" +
+		"   |       |       |\n" +
+		" <=2=>     |       |\n" +
+		"<==3==>    |      <1>\n" +
+		"Peg #0  Peg #1  Peg #2\n"
+	assert.Equal(t, want, render(t, NewRenderer(ColorNone, 0), g))
+}
+
+func TestRenderPoles(t *testing.T) {
+	g := newGame(t, 3, 3, domain.WithStartLayout(domain.ClassicStart), domain.WithRuleset(domain.MagneticRuleset{}))
+	require.NoError(t, g.MoveDisk(0, 2))
+
+	want := "" +
+		"   |       |       |\n" +
+		" <2N=>     |       |\n" +
+		"<=3N==>    |      <S>\n" +
+		"Peg #0  Peg #1  Peg #2\n"
+	assert.Equal(t, want, render(t, NewRenderer(ColorNone, 0), g))
+}
+
+func TestRenderColors(t *testing.T) {
+	g := newGame(t, 3, 1, domain.WithStartLayout(domain.ClassicStart))
+
+	for mode, want := range map[ColorMode]string{
+		ColorTrue: "\033[48;2;255;0;0m",
+		Color256:  "\033[48;5;196m",
+		Color16:   "\033[41m",
+	} {
+		out := render(t, NewRenderer(mode, 0), g)
+		assert.Contains(t, out, want+ansiBlack+" 1 "+ansiReset)
+	}
+
+	assert.NotContains(t, render(t, NewRenderer(ColorNone, 0), g), "\033")
+}
+
+func TestRenderFitsWidth(t *testing.T) {
+	for _, tc := range []struct {
+		pegs, disks uint
+		width       int
+		rows        int
+	}{
+		{pegs: 3, disks: 30, width: 80, rows: 1},
+		{pegs: 4, disks: 64, width: 40, rows: 1},
+		{pegs: 12, disks: 10, width: 40, rows: 3},
+		{pegs: 3, disks: 5, width: 5, rows: 3},
+	} {
+		g := newGame(t, tc.pegs, tc.disks, domain.WithSeed(1))
+		out := render(t, NewRenderer(ColorNone, tc.width), g)
+
+		lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+		for _, l := range lines {
+			assert.LessOrEqual(t, len(l), max(tc.width, 7), "%d pegs %d disks: %q", tc.pegs, tc.disks, l)
+		}
+		var labels int
+		for _, l := range lines {
+			if strings.Contains(l, "#") {
+				labels++
+			}
+		}
+		assert.Equal(t, tc.rows, labels, "%d pegs %d disks", tc.pegs, tc.disks)
+	}
+}
+
+func TestDetectColorMode(t *testing.T) {
+	for _, tc := range []struct {
+		env  map[string]string
+		want ColorMode
+	}{
+		{env: map[string]string{}, want: ColorNone},
+		{env: map[string]string{"TERM": "dumb"}, want: ColorNone},
+		{env: map[string]string{"TERM": "xterm-256color", "NO_COLOR": "1"}, want: ColorNone},
+		{env: map[string]string{"TERM": "xterm"}, want: Color16},
+		{env: map[string]string{"TERM": "xterm-256color"}, want: Color256},
+		{env: map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor"}, want: ColorTrue},
+	} {
+		getenv := func(k string) string { return tc.env[k] }
+		assert.Equal(t, tc.want, DetectColorMode(getenv), "%v", tc.env)
+	}
+}
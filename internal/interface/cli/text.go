@@ -6,12 +6,33 @@ You have to move all disks to one peg so they will be ordered biggest to smalles
 Commands:
 	q 		- quit
 	l		- login or register
-	n		- new game
+	n [SEED] [RULES]	- new game, the same SEED gives the same layout,
+			  RULES are standard, cyclic, adjacent, bicolor or magnetic,
+			  the current ones by default
 	p		- get list of all players
-	r		- records table (TBD)
+	r [PEGS] [DISKS]	- records table, optionally only for given board
+	s FILE		- save the game to FILE, see 'replay' command
 	m X Y		- move top disk of peg number X to peg number Y
+	u		- undo last move
+	y		- redo last undone move
+	?		- hint: suggest the next best move
+	t		- full-screen mode, arrows and space to move disks
 	h 		- print this help message`
 
 const Bye = `Have a nice day and come back later!`
 
 const EmptyInput = `Oops, empty input! Type 'h' for help.`
+
+const Usage = `Usage: hanoi [COMMAND] [FLAGS]
+
+Commands:
+	play		- play the game, it is the default command
+	solve		- print moves which win the game
+	players		- list registered players
+	records		- print the records table
+	replay FILE	- show a game saved with 's FILE' move by move
+	migrate up|down [N|all]|version	- manage database schema
+	help		- print this help message
+
+Run 'hanoi COMMAND -h' to see flags of the command.
+Example: hanoi play --pegs 4 --disks 8 --storage memory`
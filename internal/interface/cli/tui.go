@@ -0,0 +1,295 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/domain"
+)
+
+const TUIHelp = `Full-screen mode keys:
+	← → or a d	- move cursor between pegs
+	0..9		- jump to peg
+	space, enter	- pick up the top disk or drop it on the cursor peg
+	u		- undo last move
+	y		- redo last undone move
+	?		- hint: suggest the next best move
+	h		- show or hide this help
+	q, Ctrl+C	- back to command mode
+
+Press any key to close help.`
+
+const (
+	ansiClear      = "\033[H\033[2J"
+	ansiAltScreen  = "\033[?1049h"
+	ansiMainScreen = "\033[?1049l"
+	ansiHideCursor = "\033[?25l"
+	ansiShowCursor = "\033[?25h"
+)
+
+type keyCode int
+
+const (
+	keyLeft keyCode = iota
+	keyRight
+	keyPeg
+	keyAction
+	keyUndo
+	keyRedo
+	keyHint
+	keyHelp
+	keyQuit
+	keyOther
+)
+
+type key struct {
+	code keyCode
+	// peg is set for keyPeg
+	peg int
+}
+
+// decodeKeys splits raw terminal input into keys.
+func decodeKeys(buf []byte) []key {
+	var keys []key
+	for len(buf) > 0 {
+		k, n := decodeKey(buf)
+		keys = append(keys, k)
+		buf = buf[n:]
+	}
+	return keys
+}
+
+// decodeKey reads the first key of raw terminal input and tells how many
+// bytes it takes.
+func decodeKey(buf []byte) (key, int) {
+	b := buf[0]
+	switch {
+	case b == 0x1b && len(buf) > 2 && (buf[1] == '[' || buf[1] == 'O'):
+		switch buf[2] {
+		case 'C':
+			return key{code: keyRight}, 3
+		case 'D':
+			return key{code: keyLeft}, 3
+		default:
+			return key{code: keyOther}, 3
+		}
+	case b >= '0' && b <= '9':
+		return key{code: keyPeg, peg: int(b - '0')}, 1
+	case b == 'a':
+		return key{code: keyLeft}, 1
+	case b == 'd':
+		return key{code: keyRight}, 1
+	case b == ' ' || b == '\r' || b == '\n':
+		return key{code: keyAction}, 1
+	case b == 'u':
+		return key{code: keyUndo}, 1
+	case b == 'y':
+		return key{code: keyRedo}, 1
+	case b == '?':
+		return key{code: keyHint}, 1
+	case b == 'h':
+		return key{code: keyHelp}, 1
+	case b == 'q' || b == 0x03:
+		return key{code: keyQuit}, 1
+	default:
+		return key{code: keyOther}, 1
+	}
+}
+
+// TUI is the full-screen mode. It expects the terminal to be switched to raw
+// mode by the caller, so it stays testable with plain readers and writers.
+type TUI struct {
+	in       *Input
+	out      io.Writer
+	renderer *Renderer
+	game     *domain.Game
+	// OnWin is called once the game becomes won
+	OnWin func(g *domain.Game)
+
+	cursor  int
+	held    int
+	help    bool
+	message string
+	started time.Time
+	now     func() time.Time
+}
+
+// NewTUI makes the full-screen mode reading keys from in. The line mode
+// should read the same Input, then input left after the quit key, or a read
+// left running when drawing fails, is handed over to it.
+func NewTUI(in io.Reader, out io.Writer, renderer *Renderer, game *domain.Game) *TUI {
+	input, ok := in.(*Input)
+	if !ok {
+		input = NewInput(in)
+	}
+	return &TUI{
+		in:       input,
+		out:      out,
+		renderer: renderer,
+		game:     game,
+		held:     -1,
+		now:      time.Now,
+	}
+}
+
+// Run draws the game and handles keys until the player quits. The clock in
+// the status bar is redrawn every second.
+func (t *TUI) Run() error {
+	t.started = t.now()
+
+	fmt.Fprint(t.out, ansiAltScreen+ansiHideCursor)
+	defer fmt.Fprint(t.out, ansiShowCursor+ansiMainScreen)
+
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+
+	for {
+		if err := t.draw(); err != nil {
+			return err
+		}
+
+		k, ok, err := t.nextKey(tick.C)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if k.code == keyQuit {
+			return nil
+		}
+		t.handle(k)
+	}
+}
+
+// nextKey waits for a key or a tick of the clock, ok is false on a tick.
+// Only the bytes of the key are taken from the input, the end of it is the
+// quit key.
+func (t *TUI) nextKey(tick <-chan time.Time) (k key, ok bool, err error) {
+	for len(t.in.rest) == 0 {
+		if t.in.err == io.EOF {
+			return key{code: keyQuit}, true, nil
+		}
+		if t.in.err != nil {
+			return key{}, false, t.in.err
+		}
+
+		select {
+		case <-tick:
+			return key{}, false, nil
+		case c := <-t.in.next():
+			t.in.take(c)
+		}
+	}
+
+	k, n := decodeKey(t.in.rest)
+	t.in.rest = t.in.rest[n:]
+	return k, true, nil
+}
+
+func (t *TUI) handle(k key) {
+	t.message = ""
+	if t.help {
+		t.help = false
+		return
+	}
+
+	wasWon := t.game.IsWon()
+	switch k.code {
+	case keyLeft:
+		t.cursor = (t.cursor + len(t.game.Pegs) - 1) % len(t.game.Pegs)
+	case keyRight:
+		t.cursor = (t.cursor + 1) % len(t.game.Pegs)
+	case keyPeg:
+		if k.peg < len(t.game.Pegs) {
+			t.cursor = k.peg
+		}
+	case keyAction:
+		t.action()
+	case keyUndo:
+		t.held = -1
+		if _, err := t.game.Undo(); err != nil {
+			t.message = err.Error()
+		}
+	case keyRedo:
+		t.held = -1
+		if _, err := t.game.Redo(); err != nil {
+			t.message = err.Error()
+		}
+	case keyHint:
+		m, left, err := t.game.Hint()
+		if err != nil {
+			t.message = err.Error()
+			break
+		}
+		t.message = fmt.Sprintf("Try %d → %d, %d moves left to win", m.From, m.To, left)
+	case keyHelp:
+		t.help = true
+	}
+
+	if !wasWon && t.game.IsWon() {
+		t.message = fmt.Sprintf("Congratulations, %s! You've won! Steps: %d (net %d)", t.game.Player.Nickname, t.game.Step, t.game.NetSteps())
+		if t.OnWin != nil {
+			t.OnWin(t.game)
+		}
+	}
+}
+
+// action picks up the top disk of the cursor peg or drops the held one.
+func (t *TUI) action() {
+	if t.held == -1 {
+		if t.game.Pegs[t.cursor].TopDisk == nil {
+			t.message = "Peg is empty"
+			return
+		}
+		t.held = t.cursor
+		return
+	}
+
+	from := t.held
+	t.held = -1
+	if from == t.cursor {
+		return
+	}
+	if err := t.game.MoveDisk(from, t.cursor); err != nil {
+		t.message = err.Error()
+	}
+}
+
+func (t *TUI) draw() error {
+	var b bytes.Buffer
+	b.WriteString(ansiClear)
+
+	if t.help {
+		b.WriteString(TUIHelp)
+		b.WriteString("\n")
+	} else {
+		b.WriteString("Tower of Hanoi, press h for help, q to quit\n\n")
+		err := t.renderer.RenderHighlighted(&b, t.game, Highlight{Cursor: t.cursor, Held: t.held})
+		if err != nil {
+			return err
+		}
+		b.WriteString("\n")
+		b.WriteString(t.status())
+		b.WriteString("\n")
+		b.WriteString(t.message)
+		b.WriteString("\n")
+	}
+
+	// Raw mode does not return carriage on new line.
+	_, err := io.WriteString(t.out, strings.ReplaceAll(b.String(), "\n", "\r\n"))
+	return err
+}
+
+func (t *TUI) status() string {
+	elapsed := t.now().Sub(t.started).Truncate(time.Second)
+	s := fmt.Sprintf("Steps: %d  Net: %d  Time: %02d:%02d  Goal: %s  Rules: %s",
+		t.game.Step, t.game.NetSteps(), int(elapsed.Minutes()), int(elapsed.Seconds())%60, t.game.Goal, t.game.Rules.Name())
+	if t.game.HintsUsed > 0 {
+		s += fmt.Sprintf("  Hints: %d", t.game.HintsUsed)
+	}
+	return s
+}
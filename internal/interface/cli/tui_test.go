@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeKeys(t *testing.T) {
+	keys := decodeKeys([]byte("\x1b[C\x1b[D\x1bOC2 \ruy?hq\x03x"))
+
+	var codes []keyCode
+	for _, k := range keys {
+		codes = append(codes, k.code)
+	}
+	assert.Equal(t, []keyCode{
+		keyRight, keyLeft, keyRight, keyPeg, keyAction, keyAction,
+		keyUndo, keyRedo, keyHint, keyHelp, keyQuit, keyQuit, keyOther,
+	}, codes)
+	assert.Equal(t, 2, keys[3].peg)
+}
+
+func TestTUIPlay(t *testing.T) {
+	g := newGame(t, 3, 2, domain.WithStartLayout(domain.ClassicStart), domain.WithGoal(domain.PegGoal(2)))
+
+	var won int
+	var out bytes.Buffer
+	// 0 → 1 with arrow, 0 → 2 and 1 → 2 with digits, then an attempt to pick
+	// up from the empty peg
+	tui := NewTUI(strings.NewReader(" \x1b[C 0 2 1 2 0 q"), &out, NewRenderer(ColorNone, 0), g)
+	tui.OnWin = func(*domain.Game) { won++ }
+	require.NoError(t, tui.Run())
+
+	assert.True(t, g.IsWon())
+	assert.Equal(t, uint(3), g.Step)
+	assert.Equal(t, 1, won)
+	assert.Equal(t, "Peg is empty", tui.message)
+	assert.Contains(t, out.String(), "Congratulations")
+	assert.NotContains(t, strings.ReplaceAll(out.String(), "\r\n", ""), "\n")
+}
+
+func TestTUIHeldDisk(t *testing.T) {
+	g := newGame(t, 3, 2, domain.WithStartLayout(domain.ClassicStart), domain.WithGoal(domain.PegGoal(2)))
+
+	var out bytes.Buffer
+	tui := NewTUI(strings.NewReader(" \x1b[C"), &out, NewRenderer(ColorNone, 0), g)
+	require.NoError(t, tui.Run())
+	assert.Equal(t, 0, tui.held)
+	assert.Equal(t, 1, tui.cursor)
+
+	var b bytes.Buffer
+	require.NoError(t, tui.renderer.RenderHighlighted(&b, g, Highlight{Cursor: tui.cursor, Held: tui.held}))
+	want := "" +
+		"       <1>\n" +
+		"  |     |     |\n" +
+		"<=2=>   |     |\n" +
+		" #0   >#1<   #2\n"
+	assert.Equal(t, want, b.String())
+}
+
+func TestTUIHelp(t *testing.T) {
+	g := newGame(t, 3, 2, domain.WithStartLayout(domain.ClassicStart), domain.WithGoal(domain.PegGoal(2)))
+
+	var out bytes.Buffer
+	// the key closing help does nothing else
+	tui := NewTUI(strings.NewReader("h uq"), &out, NewRenderer(ColorNone, 0), g)
+	require.NoError(t, tui.Run())
+
+	assert.Contains(t, out.String(), "Full-screen mode keys")
+	assert.Equal(t, -1, tui.held)
+	assert.Equal(t, "nothing to undo", tui.message)
+}
+
+func TestTUIHandsInputOver(t *testing.T) {
+	g := newGame(t, 3, 2, domain.WithStartLayout(domain.ClassicStart), domain.WithGoal(domain.PegGoal(2)))
+
+	in := NewInput(strings.NewReader("dq\nm 0 2\n"))
+	tui := NewTUI(in, io.Discard, NewRenderer(ColorNone, 0), g)
+	require.NoError(t, tui.Run())
+	assert.Equal(t, 1, tui.cursor)
+
+	lines := bufio.NewScanner(in)
+	require.True(t, lines.Scan())
+	assert.Equal(t, "", lines.Text(), "rest of the line with the quit key")
+	require.True(t, lines.Scan())
+	assert.Equal(t, "m 0 2", lines.Text())
+}
+
+func TestInputHandsRunningReadOver(t *testing.T) {
+	r, w := io.Pipe()
+	in := NewInput(r)
+	// the full-screen mode stopped while waiting for a key
+	in.next()
+
+	go w.Write([]byte("m 0 2\n"))
+	lines := bufio.NewScanner(in)
+	require.True(t, lines.Scan())
+	assert.Equal(t, "m 0 2", lines.Text())
+	assert.False(t, in.reading)
+
+	w.Close()
+	assert.False(t, lines.Scan())
+	assert.NoError(t, lines.Err())
+}
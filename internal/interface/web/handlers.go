@@ -0,0 +1,428 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image/color"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/domain"
+)
+
+// Board size is limited, so a single request cannot eat all the memory.
+// Distance starts search every layout of the board, so they are allowed on
+// small boards only.
+const (
+	maxPegs        = 16
+	maxDisks       = 64
+	maxStartStates = 1 << 20
+)
+
+type playerRequest struct {
+	Nickname string `json:"nickname"`
+}
+
+type playerResponse struct {
+	ID       domain.PlayerID `json:"id"`
+	Nickname string          `json:"nickname"`
+}
+
+func newPlayerResponse(p *domain.Player) playerResponse {
+	return playerResponse{ID: p.ID, Nickname: p.Nickname}
+}
+
+type createGameRequest struct {
+	PlayerID domain.PlayerID `json:"player_id"`
+	Pegs     uint            `json:"pegs"`
+	Disks    uint            `json:"disks"`
+	// Seed is random when omitted
+	Seed *int64 `json:"seed,omitempty"`
+	// Start is in the format of domain.ParseStartLayout, ignored when Layout
+	// is set
+	Start  string        `json:"start,omitempty"`
+	Layout domain.Layout `json:"layout,omitempty"`
+	// Goal is in the format of domain.Goal.String, "any" by default
+	Goal string `json:"goal,omitempty"`
+	// Ruleset is one of domain.Rulesets, "standard" by default
+	Ruleset string `json:"ruleset,omitempty"`
+}
+
+type moveRequest struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+type diskResponse struct {
+	Size  uint   `json:"size"`
+	Color string `json:"color"`
+	// Pole faces up on magnetic disks, "N" or "S"
+	Pole string `json:"pole,omitempty"`
+}
+
+type gameResponse struct {
+	ID     domain.GameID  `json:"id"`
+	Player playerResponse `json:"player"`
+	// Pegs list disks from bottom to top
+	Pegs       [][]diskResponse `json:"pegs"`
+	TotalDisks int              `json:"total_disks"`
+	Steps      uint             `json:"steps"`
+	NetSteps   uint             `json:"net_steps"`
+	HintsUsed  uint             `json:"hints_used"`
+	Seed       int64            `json:"seed"`
+	Goal       string           `json:"goal"`
+	Ruleset    string           `json:"ruleset"`
+	Won        bool             `json:"won"`
+}
+
+func newGameResponse(id domain.GameID, g *domain.Game) gameResponse {
+	pegs := make([][]diskResponse, len(g.Pegs))
+	for i, p := range g.Pegs {
+		disks := []diskResponse{}
+		for d := p.TopDisk; d != nil; d = d.Next {
+			disks = append(disks, diskResponse{Size: d.Size, Color: colorHex(d.Color), Pole: d.Up.String()})
+		}
+		slices.Reverse(disks)
+		pegs[i] = disks
+	}
+
+	return gameResponse{
+		ID:         id,
+		Player:     newPlayerResponse(g.Player),
+		Pegs:       pegs,
+		TotalDisks: g.TotalDisks,
+		Steps:      g.Step,
+		NetSteps:   g.NetSteps(),
+		HintsUsed:  g.HintsUsed,
+		Seed:       g.Seed,
+		Goal:       g.Goal.String(),
+		Ruleset:    g.Rules.Name(),
+		Won:        g.IsWon(),
+	}
+}
+
+type hintResponse struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+	// Left is how many moves are left to win after this one included
+	Left int `json:"left"`
+}
+
+type recordResponse struct {
+	Player     playerResponse `json:"player"`
+	Steps      uint           `json:"steps"`
+	HintsUsed  uint           `json:"hints_used"`
+	Pegs       int            `json:"pegs"`
+	Disks      int            `json:"disks"`
+	Goal       string         `json:"goal"`
+	Ruleset    string         `json:"ruleset"`
+	AchievedAt time.Time      `json:"achieved_at"`
+}
+
+// colorHex formats disk color as "#rrggbb".
+func colorHex(c color.Color) string {
+	if c == nil {
+		return ""
+	}
+	r, g, b := domain.RGB(c)
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
+
+func (s *Server) handleGetAllPlayers(w http.ResponseWriter, r *http.Request) {
+	players, err := s.playerRepo.GetAll(r.Context())
+	if err != nil {
+		s.logger.Error("cannot get players", slog.Any("err", err))
+		writeError(w, http.StatusInternalServerError, errors.New("cannot get players"))
+		return
+	}
+
+	res := make([]playerResponse, 0, len(players))
+	for _, p := range players {
+		res = append(res, newPlayerResponse(p))
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleCreatePlayer(w http.ResponseWriter, r *http.Request) {
+	var req playerRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Nickname == "" {
+		writeError(w, http.StatusBadRequest, errors.New("nickname cannot be empty"))
+		return
+	}
+
+	_, err := s.playerRepo.GetByNickname(r.Context(), req.Nickname)
+	if err == nil {
+		writeError(w, http.StatusConflict, fmt.Errorf("player %q already exists", req.Nickname))
+		return
+	}
+	if !errors.Is(err, domain.ErrPlayerNotFound) {
+		s.logger.Error("cannot get player", slog.Any("err", err))
+		writeError(w, http.StatusInternalServerError, errors.New("cannot get player"))
+		return
+	}
+
+	id, err := s.playerRepo.Save(r.Context(), req.Nickname)
+	if err != nil {
+		var cannotCreate *domain.ErrCannotCreatePlayer
+		if errors.As(err, &cannotCreate) {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		s.logger.Error("cannot save player", slog.Any("err", err))
+		writeError(w, http.StatusInternalServerError, errors.New("cannot save player"))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, playerResponse{ID: id, Nickname: req.Nickname})
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var req playerRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	p, err := s.playerRepo.GetByNickname(r.Context(), req.Nickname)
+	if err != nil {
+		if errors.Is(err, domain.ErrPlayerNotFound) {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		s.logger.Error("cannot get player", slog.Any("err", err))
+		writeError(w, http.StatusInternalServerError, errors.New("cannot get player"))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newPlayerResponse(p))
+}
+
+func (s *Server) handleCreateGame(w http.ResponseWriter, r *http.Request) {
+	var req createGameRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	player, err := s.playerRepo.GetByID(r.Context(), req.PlayerID)
+	if err != nil {
+		if errors.Is(err, domain.ErrPlayerNotFound) {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		s.logger.Error("cannot get player", slog.Any("err", err))
+		writeError(w, http.StatusInternalServerError, errors.New("cannot get player"))
+		return
+	}
+
+	if req.Pegs > maxPegs || req.Disks > maxDisks {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("board cannot be larger than %d pegs and %d disks", maxPegs, maxDisks))
+		return
+	}
+
+	opts, err := req.options()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	g, err := domain.NewGame(req.Pegs, req.Disks, player, domain.DefaultColorPicker(), opts...)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	id := domain.NewGameID()
+	if err := s.gameRepo.Save(r.Context(), id, g); err != nil {
+		s.writeGameError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newGameResponse(id, g))
+}
+
+func (req createGameRequest) options() ([]domain.GameOption, error) {
+	var opts []domain.GameOption
+	if req.Seed != nil {
+		opts = append(opts, domain.WithSeed(*req.Seed))
+	}
+
+	if req.Layout != nil {
+		opts = append(opts, domain.WithStartLayout(domain.CustomStart(req.Layout)))
+	} else {
+		start, err := domain.ParseStartLayoutWithin(req.Start, maxStartStates)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, domain.WithStartLayout(start))
+	}
+
+	goal, err := domain.ParseGoal(req.Goal)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, domain.WithGoal(goal))
+
+	rules, err := domain.ParseRuleset(req.Ruleset)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, domain.WithRuleset(rules))
+
+	return opts, nil
+}
+
+func (s *Server) handleGetGame(w http.ResponseWriter, r *http.Request) {
+	id := domain.GameID(r.PathValue("id"))
+
+	g, err := s.gameRepo.GetByID(r.Context(), id)
+	if err != nil {
+		s.writeGameError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newGameResponse(id, g))
+}
+
+func (s *Server) handleMove(w http.ResponseWriter, r *http.Request) {
+	id := domain.GameID(r.PathValue("id"))
+
+	var req moveRequest
+	if err := readJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	g, err := s.update(r.Context(), id, func(g *domain.Game) error {
+		if err := g.MoveDisk(req.From, req.To); err != nil {
+			return &rejectedError{status: http.StatusUnprocessableEntity, err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		s.writeGameError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newGameResponse(id, g))
+}
+
+// handleUndoRedo takes back or repeats a move depending on the route.
+func (s *Server) handleUndoRedo(redo bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := domain.GameID(r.PathValue("id"))
+
+		g, err := s.update(r.Context(), id, func(g *domain.Game) error {
+			var err error
+			if redo {
+				_, err = g.Redo()
+			} else {
+				_, err = g.Undo()
+			}
+			if err != nil {
+				return &rejectedError{status: http.StatusConflict, err: err}
+			}
+			return nil
+		})
+		if err != nil {
+			s.writeGameError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, newGameResponse(id, g))
+	}
+}
+
+func (s *Server) handleHint(w http.ResponseWriter, r *http.Request) {
+	id := domain.GameID(r.PathValue("id"))
+
+	res, err := s.hint(r.Context(), id)
+	if err != nil {
+		s.writeGameError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// saveRecord stores the record of just won game. Failure is only logged,
+// the player has won anyway.
+func (s *Server) saveRecord(ctx context.Context, g *domain.Game) {
+	if s.recordRepo == nil {
+		return
+	}
+
+	rec, err := domain.NewRecord(g)
+	if err != nil {
+		s.logger.Error("cannot make a record", slog.Any("err", err))
+		return
+	}
+
+	if _, err := s.recordRepo.Save(ctx, rec); err != nil {
+		s.logger.Error("cannot save the record", slog.Any("err", err))
+	}
+}
+
+func (s *Server) handleGetRecords(w http.ResponseWriter, r *http.Request) {
+	if s.recordRepo == nil {
+		writeError(w, http.StatusNotImplemented, errors.New("records are not available"))
+		return
+	}
+
+	// records of different rules and goals are ranked apart, the standard
+	// rules and any peg by default
+	rules, err := domain.ParseRuleset(r.URL.Query().Get("ruleset"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	goal, err := domain.ParseGoal(r.URL.Query().Get("goal"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	filter := domain.RecordFilter{Ruleset: rules.Name(), Goal: goal.String()}
+	for name, dst := range map[string]*int{"pegs": &filter.Pegs, "disks": &filter.Disks, "limit": &filter.Limit} {
+		v := r.URL.Query().Get(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("%s is not a number: %w", name, err))
+			return
+		}
+		*dst = n
+	}
+
+	records, err := s.recordRepo.GetTop(r.Context(), filter)
+	if err != nil {
+		s.logger.Error("cannot get records", slog.Any("err", err))
+		writeError(w, http.StatusInternalServerError, errors.New("cannot get records"))
+		return
+	}
+
+	res := make([]recordResponse, 0, len(records))
+	for _, rec := range records {
+		res = append(res, recordResponse{
+			Player:     playerResponse{ID: rec.PlayerID, Nickname: rec.Nickname},
+			Steps:      rec.Steps,
+			HintsUsed:  rec.HintsUsed,
+			Pegs:       rec.Pegs,
+			Disks:      rec.Disks,
+			Goal:       rec.Goal,
+			Ruleset:    rec.Ruleset,
+			AchievedAt: rec.AchievedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, res)
+}
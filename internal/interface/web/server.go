@@ -0,0 +1,260 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/domain"
+)
+
+// Server exposes the game over JSON HTTP API and serves the browser
+// front-end.
+type Server struct {
+	logger     *slog.Logger
+	playerRepo domain.PlayerRepository
+	recordRepo domain.RecordRepository
+	gameRepo   domain.GameRepository
+
+	// lock guards games, every game being updated has its own lock there
+	lock  sync.Mutex
+	games map[domain.GameID]*gameLock
+	hub   *hub
+
+	mux *http.ServeMux
+}
+
+func NewServer(logger *slog.Logger, playerRepo domain.PlayerRepository, recordRepo domain.RecordRepository, gameRepo domain.GameRepository) *Server {
+	s := &Server{
+		logger:     logger,
+		playerRepo: playerRepo,
+		recordRepo: recordRepo,
+		gameRepo:   gameRepo,
+		games:      make(map[domain.GameID]*gameLock),
+		hub:        newHub(),
+		mux:        http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /api/players", s.handleGetAllPlayers)
+	s.mux.HandleFunc("POST /api/players", s.handleCreatePlayer)
+	s.mux.HandleFunc("POST /api/login", s.handleLogin)
+	s.mux.HandleFunc("POST /api/games", s.handleCreateGame)
+	s.mux.HandleFunc("GET /api/games/{id}", s.handleGetGame)
+	s.mux.HandleFunc("POST /api/games/{id}/moves", s.handleMove)
+	s.mux.HandleFunc("POST /api/games/{id}/undo", s.handleUndoRedo(false))
+	s.mux.HandleFunc("POST /api/games/{id}/redo", s.handleUndoRedo(true))
+	s.mux.HandleFunc("POST /api/games/{id}/hint", s.handleHint)
+	s.mux.HandleFunc("GET /api/games/{id}/ws", s.handleWebSocket)
+	s.mux.HandleFunc("GET /api/records", s.handleGetRecords)
+	s.mux.Handle("GET /", staticHandler())
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// gameLock serializes updates of a single game. users counts who holds or
+// waits for it, so it is dropped once nobody does.
+type gameLock struct {
+	sync.Mutex
+	users int
+}
+
+// lockGame locks updates of the game, other games are not blocked. Returns
+// the unlock function.
+func (s *Server) lockGame(id domain.GameID) func() {
+	s.lock.Lock()
+	l, ok := s.games[id]
+	if !ok {
+		l = &gameLock{}
+		s.games[id] = l
+	}
+	l.users++
+	s.lock.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+
+		s.lock.Lock()
+		l.users--
+		if l.users == 0 {
+			delete(s.games, id)
+		}
+		s.lock.Unlock()
+	}
+}
+
+// update loads the game, runs fn and saves the game back unless fn fails.
+// Updates of a game are serialized, so concurrent moves are not lost, fn
+// should be quick as it holds them up. When the game becomes won the record
+// is saved. Subscribers get the new state.
+func (s *Server) update(ctx context.Context, id domain.GameID, fn func(g *domain.Game) error) (*domain.Game, error) {
+	defer s.lockGame(id)()
+
+	g, err := s.gameRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	wasWon := g.IsWon()
+	if err := fn(g); err != nil {
+		return nil, err
+	}
+
+	if err := s.gameRepo.Save(ctx, id, g); err != nil {
+		return nil, err
+	}
+
+	won := !wasWon && g.IsWon()
+	if won {
+		s.saveRecord(ctx, g)
+	}
+	s.hub.publish(id, won, newGameResponse(id, g))
+
+	return g, nil
+}
+
+var errGameChanged = errors.New("game has changed while the hint was searched, ask again")
+
+// hint finds the next best move. The search may take long, so it runs on
+// a copy of the game without holding its updates, and the hint is counted
+// only if nobody has moved meanwhile.
+func (s *Server) hint(ctx context.Context, id domain.GameID) (hintResponse, error) {
+	g, err := s.gameRepo.GetByID(ctx, id)
+	if err != nil {
+		return hintResponse{}, err
+	}
+
+	m, left, err := domain.NextBestMove(g)
+	if err != nil {
+		return hintResponse{}, &rejectedError{status: http.StatusUnprocessableEntity, err: err}
+	}
+
+	step := g.Step
+	_, err = s.update(ctx, id, func(g *domain.Game) error {
+		if g.Step != step {
+			return &rejectedError{status: http.StatusConflict, err: errGameChanged}
+		}
+		g.HintsUsed++
+		return nil
+	})
+	if err != nil {
+		return hintResponse{}, err
+	}
+
+	return hintResponse{From: m.From, To: m.To, Left: left}, nil
+}
+
+// ExpireGames removes games nobody has touched for ttl, checking every
+// interval until ctx is done.
+func (s *Server) ExpireGames(ctx context.Context, ttl time.Duration, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			n, err := s.gameRepo.DeleteExpired(ctx, now.Add(-ttl))
+			if err != nil {
+				s.logger.Error("cannot delete expired games", slog.Any("err", err))
+				continue
+			}
+			if n > 0 {
+				s.logger.Info("expired games deleted", slog.Int("count", n))
+			}
+		}
+	}
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+	// Code is a stable machine readable reason, see errorCode
+	Code string `json:"code,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error(), Code: errorCode(err)})
+}
+
+// errorCode tells known errors apart for clients, empty when the error is
+// not known.
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrPegOutOfRange):
+		return "peg_out_of_range"
+	case errors.Is(err, domain.ErrEmptyPeg):
+		return "empty_peg"
+	case errors.Is(err, domain.ErrBiggerOnSmaller):
+		return "bigger_on_smaller"
+	case errors.Is(err, domain.ErrIllegalMove):
+		return "illegal_move"
+	case errors.Is(err, domain.ErrLikePoles):
+		return "like_poles"
+	case errors.Is(err, domain.ErrNothingToUndo):
+		return "nothing_to_undo"
+	case errors.Is(err, domain.ErrNothingToRedo):
+		return "nothing_to_redo"
+	case errors.Is(err, domain.ErrAlreadyWon):
+		return "already_won"
+	case errors.Is(err, domain.ErrGameNotFound):
+		return "game_not_found"
+	case errors.Is(err, domain.ErrPlayerNotFound):
+		return "player_not_found"
+	case errors.Is(err, errGameChanged):
+		return "game_changed"
+	default:
+		return ""
+	}
+}
+
+// rejectedError is an action refused by the game rather than a storage
+// failure. The game is not saved then.
+type rejectedError struct {
+	status int
+	err    error
+}
+
+func (e *rejectedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *rejectedError) Unwrap() error {
+	return e.err
+}
+
+// writeGameError reports failure of loading, changing or saving the game.
+func (s *Server) writeGameError(w http.ResponseWriter, err error) {
+	var rejected *rejectedError
+	if errors.As(err, &rejected) {
+		writeError(w, rejected.status, rejected.err)
+		return
+	}
+	if errors.Is(err, domain.ErrGameNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	s.logger.Error("cannot access game", slog.Any("err", err))
+	writeError(w, http.StatusInternalServerError, errors.New("cannot access game"))
+}
+
+// readJSON decodes request body into v, unknown fields are rejected.
+func readJSON(r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
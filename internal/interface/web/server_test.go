@@ -0,0 +1,229 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/infrastructure/persistance/inmemory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	srv := httptest.NewServer(NewServer(logger, inmemory.NewPlayerInmemoryRepo(logger), inmemory.NewRecordInmemoryRepo(logger), inmemory.NewGameInmemoryRepo(logger)))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGameIsKeptInRepository(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	players := inmemory.NewPlayerInmemoryRepo(logger)
+	games := inmemory.NewGameInmemoryRepo(logger)
+
+	srv := httptest.NewServer(NewServer(logger, players, nil, games))
+	var p playerResponse
+	call(t, srv, http.MethodPost, "/api/players", playerRequest{Nickname: "anru"}, http.StatusCreated, &p)
+
+	var g gameResponse
+	call(t, srv, http.MethodPost, "/api/games", createGameRequest{PlayerID: p.ID, Pegs: 3, Disks: 3, Start: "classic", Goal: "peg:2"}, http.StatusCreated, &g)
+	call(t, srv, http.MethodPost, "/api/games/"+string(g.ID)+"/moves", moveRequest{From: 0, To: 2}, http.StatusOK, &g)
+	call(t, srv, http.MethodPost, "/api/games/"+string(g.ID)+"/hint", nil, http.StatusOK, nil)
+	srv.Close()
+
+	srv = httptest.NewServer(NewServer(logger, players, nil, games))
+	defer srv.Close()
+
+	var restored gameResponse
+	call(t, srv, http.MethodGet, "/api/games/"+string(g.ID), nil, http.StatusOK, &restored)
+	assert.Equal(t, g.Pegs, restored.Pegs)
+	assert.Equal(t, uint(1), restored.Steps)
+	assert.Equal(t, uint(1), restored.HintsUsed)
+
+	n, err := games.DeleteExpired(context.Background(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	n, err = games.DeleteExpired(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	call(t, srv, http.MethodGet, "/api/games/"+string(g.ID), nil, http.StatusNotFound, nil)
+}
+
+func call(t *testing.T, srv *httptest.Server, method string, path string, body any, want int, res any) {
+	t.Helper()
+
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		r = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, srv.URL+path, r)
+	require.NoError(t, err)
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, want, resp.StatusCode)
+	if res != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(res))
+	}
+}
+
+func TestUpdateLocksPerGame(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewServer(logger, inmemory.NewPlayerInmemoryRepo(logger), nil, inmemory.NewGameInmemoryRepo(logger))
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	var p playerResponse
+	call(t, srv, http.MethodPost, "/api/players", playerRequest{Nickname: "anru"}, http.StatusCreated, &p)
+	var busy, free gameResponse
+	req := createGameRequest{PlayerID: p.ID, Pegs: 3, Disks: 3, Start: "classic", Goal: "peg:2"}
+	call(t, srv, http.MethodPost, "/api/games", req, http.StatusCreated, &busy)
+	call(t, srv, http.MethodPost, "/api/games", req, http.StatusCreated, &free)
+
+	// a long update, e.g. a hint search, holds the busy game
+	unlock := s.lockGame(busy.ID)
+	moved := make(chan struct{})
+	go func() {
+		defer close(moved)
+		call(t, srv, http.MethodPost, "/api/games/"+string(busy.ID)+"/moves", moveRequest{From: 0, To: 2}, http.StatusOK, nil)
+	}()
+
+	call(t, srv, http.MethodPost, "/api/games/"+string(free.ID)+"/moves", moveRequest{From: 0, To: 2}, http.StatusOK, nil)
+	select {
+	case <-moved:
+		t.Fatal("busy game was moved while locked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-moved:
+	case <-time.After(5 * time.Second):
+		t.Fatal("busy game was not moved after unlock")
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	assert.Empty(t, s.games, "locks of idle games are dropped")
+}
+
+func TestPlayGame(t *testing.T) {
+	srv := newTestServer(t)
+
+	var p playerResponse
+	call(t, srv, http.MethodPost, "/api/players", playerRequest{Nickname: "anru"}, http.StatusCreated, &p)
+	call(t, srv, http.MethodPost, "/api/players", playerRequest{Nickname: "anru"}, http.StatusConflict, nil)
+	call(t, srv, http.MethodPost, "/api/login", playerRequest{Nickname: "nobody"}, http.StatusNotFound, nil)
+
+	var logged playerResponse
+	call(t, srv, http.MethodPost, "/api/login", playerRequest{Nickname: "anru"}, http.StatusOK, &logged)
+	assert.Equal(t, p, logged)
+
+	var g gameResponse
+	req := createGameRequest{PlayerID: p.ID, Pegs: 3, Disks: 2, Start: "classic", Goal: "peg:2"}
+	call(t, srv, http.MethodPost, "/api/games", req, http.StatusCreated, &g)
+	assert.Len(t, g.Pegs[0], 2)
+	assert.Equal(t, uint(2), g.Pegs[0][0].Size)
+	assert.Equal(t, "#ff0000", g.Pegs[0][0].Color)
+	assert.False(t, g.Won)
+
+	call(t, srv, http.MethodPost, "/api/games/"+string(g.ID)+"/moves", moveRequest{From: 1, To: 0}, http.StatusUnprocessableEntity, nil)
+	call(t, srv, http.MethodPost, "/api/games/nope/moves", moveRequest{From: 0, To: 1}, http.StatusNotFound, nil)
+
+	for _, m := range []moveRequest{{0, 1}, {0, 2}, {1, 2}} {
+		call(t, srv, http.MethodPost, "/api/games/"+string(g.ID)+"/moves", m, http.StatusOK, &g)
+	}
+	assert.True(t, g.Won)
+	assert.Equal(t, uint(3), g.Steps)
+
+	call(t, srv, http.MethodGet, "/api/games/"+string(g.ID), nil, http.StatusOK, &g)
+	assert.True(t, g.Won)
+
+	var records []recordResponse
+	call(t, srv, http.MethodGet, "/api/records?pegs=3&disks=2&goal=peg:2", nil, http.StatusOK, &records)
+	require.Len(t, records, 1)
+	assert.Equal(t, "anru", records[0].Player.Nickname)
+	assert.Equal(t, uint(3), records[0].Steps)
+	assert.Equal(t, "peg:2", records[0].Goal)
+
+	call(t, srv, http.MethodGet, "/api/records?pegs=3&disks=2", nil, http.StatusOK, &records)
+	assert.Empty(t, records, "any peg goal by default")
+	call(t, srv, http.MethodGet, "/api/records?goal=nope", nil, http.StatusBadRequest, nil)
+	call(t, srv, http.MethodGet, "/api/records?pegs=3&disks=2&goal=peg:2&ruleset=standard", nil, http.StatusOK, &records)
+	assert.Len(t, records, 1)
+	call(t, srv, http.MethodGet, "/api/records?pegs=3&disks=2&goal=peg:2&ruleset=cyclic", nil, http.StatusOK, &records)
+	assert.Empty(t, records, "rulesets are ranked apart")
+	call(t, srv, http.MethodGet, "/api/records?ruleset=nope", nil, http.StatusBadRequest, nil)
+
+	call(t, srv, http.MethodGet, "/api/records?pegs=4", nil, http.StatusOK, &records)
+	assert.Empty(t, records)
+	call(t, srv, http.MethodGet, "/api/records?pegs=x", nil, http.StatusBadRequest, nil)
+}
+
+func TestCreateGameErrors(t *testing.T) {
+	srv := newTestServer(t)
+
+	var p playerResponse
+	call(t, srv, http.MethodPost, "/api/players", playerRequest{Nickname: "anru"}, http.StatusCreated, &p)
+
+	call(t, srv, http.MethodPost, "/api/games", createGameRequest{PlayerID: 42, Pegs: 3, Disks: 3}, http.StatusNotFound, nil)
+	call(t, srv, http.MethodPost, "/api/games", createGameRequest{PlayerID: p.ID, Pegs: 0, Disks: 3}, http.StatusBadRequest, nil)
+	call(t, srv, http.MethodPost, "/api/games", createGameRequest{PlayerID: p.ID, Pegs: 3, Disks: 1000}, http.StatusBadRequest, nil)
+	call(t, srv, http.MethodPost, "/api/games", createGameRequest{PlayerID: p.ID, Pegs: 3, Disks: 3, Start: "weird"}, http.StatusBadRequest, nil)
+	// searching every layout of a big board would take seconds
+	call(t, srv, http.MethodPost, "/api/games", createGameRequest{PlayerID: p.ID, Pegs: 16, Disks: 6, Start: "distance:40"}, http.StatusBadRequest, nil)
+	call(t, srv, http.MethodPost, "/api/games", createGameRequest{PlayerID: p.ID, Pegs: 4, Disks: 5, Start: "distance:5"}, http.StatusCreated, nil)
+	call(t, srv, http.MethodPost, "/api/games", createGameRequest{PlayerID: p.ID, Pegs: 3, Disks: 3, Goal: "peg:5"}, http.StatusBadRequest, nil)
+	call(t, srv, http.MethodPost, "/api/games", createGameRequest{PlayerID: p.ID, Pegs: 3, Disks: 3, Ruleset: "weird"}, http.StatusBadRequest, nil)
+}
+
+func TestUndoRedoHint(t *testing.T) {
+	srv := newTestServer(t)
+
+	var p playerResponse
+	call(t, srv, http.MethodPost, "/api/players", playerRequest{Nickname: "anru"}, http.StatusCreated, &p)
+
+	var g gameResponse
+	call(t, srv, http.MethodPost, "/api/games", createGameRequest{PlayerID: p.ID, Pegs: 3, Disks: 3, Start: "classic", Goal: "peg:2"}, http.StatusCreated, &g)
+	call(t, srv, http.MethodPost, "/api/games/"+string(g.ID)+"/undo", nil, http.StatusConflict, nil)
+
+	var h hintResponse
+	call(t, srv, http.MethodPost, "/api/games/"+string(g.ID)+"/hint", nil, http.StatusOK, &h)
+	assert.Equal(t, 7, h.Left)
+
+	call(t, srv, http.MethodPost, "/api/games/"+string(g.ID)+"/moves", moveRequest{From: h.From, To: h.To}, http.StatusOK, &g)
+	call(t, srv, http.MethodPost, "/api/games/"+string(g.ID)+"/undo", nil, http.StatusOK, &g)
+	assert.Equal(t, uint(0), g.NetSteps)
+	call(t, srv, http.MethodPost, "/api/games/"+string(g.ID)+"/redo", nil, http.StatusOK, &g)
+	assert.Equal(t, uint(1), g.NetSteps)
+	assert.Equal(t, uint(3), g.Steps)
+	assert.Equal(t, uint(1), g.HintsUsed)
+}
+
+func TestStatic(t *testing.T) {
+	srv := newTestServer(t)
+
+	for _, path := range []string{"/", "/app.js", "/style.css"} {
+		resp, err := srv.Client().Get(srv.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+	}
+
+	resp, err := srv.Client().Get(srv.URL + "/api/nothing")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
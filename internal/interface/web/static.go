@@ -0,0 +1,20 @@
+package web
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var static embed.FS
+
+// staticHandler serves the browser front-end built into the binary.
+func staticHandler() http.Handler {
+	root, err := fs.Sub(static, "static")
+	if err != nil {
+		// static is embedded at build time, so it is always there
+		panic(err)
+	}
+	return http.FileServerFS(root)
+}
@@ -0,0 +1,246 @@
+"use strict";
+
+const $ = (id) => document.getElementById(id);
+
+let player = null;
+let game = null;
+let socket = null;
+// selected is the peg picked by click, for those who do not drag
+let selected = null;
+
+async function api(method, path, body) {
+  const res = await fetch(path, {
+    method,
+    headers: body ? { "Content-Type": "application/json" } : {},
+    body: body ? JSON.stringify(body) : undefined,
+  });
+  const data = await res.json();
+  if (!res.ok) {
+    const err = new Error(data.error);
+    err.status = res.status;
+    throw err;
+  }
+  return data;
+}
+
+function say(text, kind) {
+  $("message").textContent = text;
+  $("message").className = kind || "";
+}
+
+async function login(nickname) {
+  try {
+    player = await api("POST", "/api/login", { nickname });
+  } catch (err) {
+    if (err.status !== 404) {
+      throw err;
+    }
+    player = await api("POST", "/api/players", { nickname });
+  }
+
+  localStorage.setItem("nickname", nickname);
+  $("who").textContent = player.nickname;
+  $("login").hidden = true;
+  $("new-game").hidden = false;
+}
+
+async function newGame() {
+  const req = {
+    player_id: player.id,
+    pegs: Number($("pegs").value),
+    disks: Number($("disks").value),
+    start: $("start").value,
+    goal: $("goal").value,
+    ruleset: $("ruleset").value,
+  };
+  if ($("seed").value !== "") {
+    req.seed = Number($("seed").value);
+  }
+
+  const g = await api("POST", "/api/games", req);
+  localStorage.setItem("game", g.id);
+  connect(g);
+}
+
+// connect watches the game over websocket, moves are sent there too.
+function connect(g) {
+  if (socket) {
+    socket.onclose = null;
+    socket.close();
+  }
+
+  game = g;
+  selected = null;
+  $("game").hidden = false;
+  say("");
+  render();
+  loadRecords();
+
+  const proto = location.protocol === "https:" ? "wss:" : "ws:";
+  socket = new WebSocket(`${proto}//${location.host}/api/games/${g.id}/ws`);
+  socket.onmessage = (e) => {
+    const msg = JSON.parse(e.data);
+    switch (msg.type) {
+      case "state":
+        game = msg.game;
+        render();
+        break;
+      case "won":
+        game = msg.game;
+        render();
+        say(`Congratulations, ${game.player.nickname}! You've won in ${game.steps} steps.`, "won");
+        loadRecords();
+        break;
+      case "hint":
+        say(`Try ${msg.hint.from} → ${msg.hint.to}, ${msg.hint.left} moves left to win`);
+        highlight(msg.hint.from);
+        break;
+      case "error":
+        say(msg.error.error, "error");
+        break;
+    }
+  };
+  socket.onclose = () => say("Connection lost, reload the page to continue", "error");
+}
+
+function send(msg) {
+  if (socket && socket.readyState === WebSocket.OPEN) {
+    socket.send(JSON.stringify(msg));
+  }
+}
+
+function move(from, to) {
+  if (from !== to) {
+    send({ type: "move", from, to });
+  }
+}
+
+function highlight(peg) {
+  const el = $("board").children[peg];
+  if (el) {
+    el.classList.add("selected");
+    setTimeout(() => el.classList.remove("selected"), 800);
+  }
+}
+
+function render() {
+  $("steps").textContent = game.steps;
+  $("net-steps").textContent = game.net_steps;
+  $("hints").textContent = game.hints_used;
+  $("game-seed").textContent = game.seed;
+  $("game-goal").textContent = game.goal;
+  $("game-ruleset").textContent = game.ruleset;
+
+  const board = $("board");
+  board.replaceChildren();
+  game.pegs.forEach((disks, i) => {
+    const peg = document.createElement("div");
+    peg.className = "peg";
+    peg.classList.toggle("selected", selected === i);
+    peg.style.minHeight = `${game.total_disks * 20 + 40}px`;
+
+    disks.forEach((d, j) => {
+      const disk = document.createElement("div");
+      disk.className = "disk";
+      disk.textContent = d.size + (d.pole || "");
+      disk.style.width = `${20 + (80 * d.size) / game.total_disks}%`;
+      disk.style.background = d.color || "#999";
+      if (j === disks.length - 1) {
+        disk.draggable = true;
+        disk.addEventListener("dragstart", (e) => {
+          e.dataTransfer.setData("text/plain", String(i));
+          e.dataTransfer.effectAllowed = "move";
+        });
+      }
+      peg.append(disk);
+    });
+
+    const label = document.createElement("span");
+    label.className = "label";
+    label.textContent = `#${i}`;
+    peg.append(label);
+
+    peg.addEventListener("dragover", (e) => {
+      e.preventDefault();
+      peg.classList.add("over");
+    });
+    peg.addEventListener("dragleave", () => peg.classList.remove("over"));
+    peg.addEventListener("drop", (e) => {
+      e.preventDefault();
+      peg.classList.remove("over");
+      move(Number(e.dataTransfer.getData("text/plain")), i);
+    });
+    peg.addEventListener("click", () => {
+      if (selected === null) {
+        selected = disks.length > 0 ? i : null;
+      } else {
+        move(selected, i);
+        selected = null;
+      }
+      render();
+    });
+
+    board.append(peg);
+  });
+}
+
+async function loadRecords() {
+  const q = game ? `?pegs=${game.pegs.length}&disks=${game.total_disks}&ruleset=${game.ruleset}&goal=${encodeURIComponent(game.goal)}` : "";
+  let records = [];
+  try {
+    records = await api("GET", `/api/records${q}`);
+  } catch (err) {
+    say(err.message, "error");
+  }
+
+  const body = $("records-body");
+  body.replaceChildren();
+  records.forEach((r, i) => {
+    const row = document.createElement("tr");
+    const cells = [i + 1, r.player.nickname, r.steps, r.hints_used, r.pegs, r.disks, r.goal, r.ruleset, new Date(r.achieved_at).toLocaleDateString()];
+    for (const c of cells) {
+      const td = document.createElement("td");
+      td.textContent = c;
+      row.append(td);
+    }
+    body.append(row);
+  });
+}
+
+$("login-form").addEventListener("submit", (e) => {
+  e.preventDefault();
+  login($("nickname").value.trim()).catch((err) => alert(err.message));
+});
+
+$("game-form").addEventListener("submit", (e) => {
+  e.preventDefault();
+  newGame().catch((err) => say(err.message, "error"));
+});
+
+$("undo").addEventListener("click", () => send({ type: "undo" }));
+$("redo").addEventListener("click", () => send({ type: "redo" }));
+$("hint").addEventListener("click", () => send({ type: "hint" }));
+
+// Come back to the last game after reload.
+(async () => {
+  loadRecords();
+
+  const nickname = localStorage.getItem("nickname");
+  if (!nickname) {
+    return;
+  }
+  $("nickname").value = nickname;
+  await login(nickname);
+
+  const id = localStorage.getItem("game");
+  if (id) {
+    try {
+      const g = await api("GET", `/api/games/${id}`);
+      if (g.player.id === player.id) {
+        connect(g);
+      }
+    } catch {
+      localStorage.removeItem("game");
+    }
+  }
+})().catch((err) => say(err.message, "error"));
@@ -0,0 +1,236 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/AnruKitakaze/tower-of-hanoi/internal/domain"
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+const wsWriteTimeout = 5 * time.Second
+
+// Messages sent by clients.
+const (
+	wsMove = "move"
+	wsUndo = "undo"
+	wsRedo = "redo"
+	wsHint = "hint"
+)
+
+// Messages sent by server.
+const (
+	wsState = "state"
+	wsWon   = "won"
+	wsError = "error"
+)
+
+type wsRequest struct {
+	Type string `json:"type"`
+	From int    `json:"from"`
+	To   int    `json:"to"`
+}
+
+type wsResponse struct {
+	Type  string         `json:"type"`
+	Game  *gameResponse  `json:"game,omitempty"`
+	Hint  *hintResponse  `json:"hint,omitempty"`
+	Error *errorResponse `json:"error,omitempty"`
+}
+
+// hub delivers game states to everybody watching the game.
+type hub struct {
+	lock sync.Mutex
+	subs map[domain.GameID]map[chan wsResponse]struct{}
+}
+
+func newHub() *hub {
+	return &hub{subs: make(map[domain.GameID]map[chan wsResponse]struct{})}
+}
+
+func (h *hub) subscribe(id domain.GameID) (<-chan wsResponse, func()) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	ch := make(chan wsResponse, 16)
+	if h.subs[id] == nil {
+		h.subs[id] = make(map[chan wsResponse]struct{})
+	}
+	h.subs[id][ch] = struct{}{}
+
+	return ch, func() {
+		h.lock.Lock()
+		defer h.lock.Unlock()
+
+		delete(h.subs[id], ch)
+		if len(h.subs[id]) == 0 {
+			delete(h.subs, id)
+		}
+	}
+}
+
+// publish never blocks. Every state is a full snapshot, so a slow client
+// which misses some of them just sees the next one.
+func (h *hub) publish(id domain.GameID, won bool, g gameResponse) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	msg := wsResponse{Type: wsState, Game: &g}
+	if won {
+		msg.Type = wsWon
+	}
+
+	for ch := range h.subs[id] {
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+}
+
+// handleWebSocket lets a client play the game live. Client sends wsRequest
+// messages and gets the state of the game after every change made by anyone.
+// Spectators connect with ?spectate and cannot change the game.
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	id := domain.GameID(r.PathValue("id"))
+	spectator := r.URL.Query().Has("spectate")
+
+	g, err := s.gameRepo.GetByID(r.Context(), id)
+	if err != nil {
+		s.writeGameError(w, err)
+		return
+	}
+
+	// Server timeouts are meant for plain requests, websocket lives longer.
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		s.logger.Debug("websocket handshake failed", slog.Any("err", err))
+		return
+	}
+	defer conn.CloseNow()
+
+	updates, unsubscribe := s.hub.subscribe(id)
+	defer unsubscribe()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	state := newGameResponse(id, g)
+	if err := s.wsWrite(ctx, conn, wsResponse{Type: wsState, Game: &state}); err != nil {
+		return
+	}
+
+	go func() {
+		defer cancel()
+		for {
+			_, data, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+
+			res, ok := s.wsHandle(ctx, id, spectator, data)
+			if !ok {
+				continue
+			}
+			if err := s.wsWrite(ctx, conn, res); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			conn.Close(websocket.StatusNormalClosure, "")
+			return
+		case msg := <-updates:
+			if err := s.wsWrite(ctx, conn, msg); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// wsHandle applies a client message. Result of successful change comes to
+// the client as any other update, so only hints and errors are returned.
+func (s *Server) wsHandle(ctx context.Context, id domain.GameID, spectator bool, data []byte) (wsResponse, bool) {
+	var req wsRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return wsErrorResponse("bad_message", fmt.Errorf("cannot parse message: %w", err)), true
+	}
+
+	if spectator {
+		return wsErrorResponse("read_only", errors.New("spectators cannot change the game")), true
+	}
+
+	var hint hintResponse
+	var err error
+	if req.Type == wsHint {
+		hint, err = s.hint(ctx, id)
+	} else {
+		_, err = s.update(ctx, id, func(g *domain.Game) error {
+			var err error
+			switch req.Type {
+			case wsMove:
+				err = g.MoveDisk(req.From, req.To)
+			case wsUndo:
+				_, err = g.Undo()
+			case wsRedo:
+				_, err = g.Redo()
+			default:
+				err = fmt.Errorf("unknown message type %q", req.Type)
+			}
+			if err != nil {
+				return &rejectedError{err: err}
+			}
+			return nil
+		})
+	}
+
+	var rejected *rejectedError
+	switch {
+	case errors.As(err, &rejected):
+		code := errorCode(rejected.err)
+		if code == "" {
+			code = "bad_message"
+		}
+		return wsErrorResponse(code, rejected.err), true
+	case err != nil:
+		s.logger.Error("cannot access game", slog.Any("err", err))
+		code := errorCode(err)
+		if code == "" {
+			code, err = "internal", errors.New("cannot access game")
+		}
+		return wsErrorResponse(code, err), true
+	case req.Type == wsHint:
+		return wsResponse{Type: wsHint, Hint: &hint}, true
+	default:
+		return wsResponse{}, false
+	}
+}
+
+func wsErrorResponse(code string, err error) wsResponse {
+	return wsResponse{Type: wsError, Error: &errorResponse{Error: err.Error(), Code: code}}
+}
+
+func (s *Server) wsWrite(ctx context.Context, conn *websocket.Conn, msg wsResponse) error {
+	ctx, cancel := context.WithTimeout(ctx, wsWriteTimeout)
+	defer cancel()
+
+	if err := wsjson.Write(ctx, conn, msg); err != nil {
+		s.logger.Debug("cannot write to websocket", slog.Any("err", err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dial(t *testing.T, ctx context.Context, url string) *websocket.Conn {
+	t.Helper()
+
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(url, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.CloseNow() })
+	return conn
+}
+
+func read(t *testing.T, ctx context.Context, conn *websocket.Conn) wsResponse {
+	t.Helper()
+
+	var msg wsResponse
+	require.NoError(t, wsjson.Read(ctx, conn, &msg))
+	return msg
+}
+
+func TestWebSocket(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	srv := newTestServer(t)
+
+	var p playerResponse
+	call(t, srv, http.MethodPost, "/api/players", playerRequest{Nickname: "anru"}, http.StatusCreated, &p)
+	var g gameResponse
+	call(t, srv, http.MethodPost, "/api/games", createGameRequest{PlayerID: p.ID, Pegs: 3, Disks: 2, Start: "classic", Goal: "peg:2"}, http.StatusCreated, &g)
+
+	url := srv.URL + "/api/games/" + string(g.ID) + "/ws"
+	player := dial(t, ctx, url)
+	spectator := dial(t, ctx, url+"?spectate")
+
+	assert.Equal(t, wsState, read(t, ctx, player).Type)
+	assert.Equal(t, wsState, read(t, ctx, spectator).Type)
+
+	require.NoError(t, wsjson.Write(ctx, spectator, wsRequest{Type: wsMove, From: 0, To: 1}))
+	msg := read(t, ctx, spectator)
+	assert.Equal(t, wsError, msg.Type)
+	assert.Equal(t, "read_only", msg.Error.Code)
+
+	require.NoError(t, wsjson.Write(ctx, player, wsRequest{Type: wsMove, From: 1, To: 2}))
+	msg = read(t, ctx, player)
+	assert.Equal(t, wsError, msg.Type)
+	assert.Equal(t, "empty_peg", msg.Error.Code)
+
+	require.NoError(t, player.Write(ctx, websocket.MessageText, []byte("{")))
+	assert.Equal(t, "bad_message", read(t, ctx, player).Error.Code)
+
+	require.NoError(t, wsjson.Write(ctx, player, wsRequest{Type: wsHint}))
+	msg = read(t, ctx, player)
+	assert.Equal(t, wsHint, msg.Type)
+	assert.Equal(t, 3, msg.Hint.Left)
+	// hint is counted, so everybody gets the new state
+	assert.Equal(t, uint(1), read(t, ctx, player).Game.HintsUsed)
+	assert.Equal(t, uint(1), read(t, ctx, spectator).Game.HintsUsed)
+
+	require.NoError(t, wsjson.Write(ctx, player, wsRequest{Type: wsMove, From: 0, To: 1}))
+	assert.Equal(t, uint(1), read(t, ctx, player).Game.Steps)
+	assert.Equal(t, uint(1), read(t, ctx, spectator).Game.Steps)
+
+	require.NoError(t, wsjson.Write(ctx, player, wsRequest{Type: wsMove, From: 0, To: 1}))
+	assert.Equal(t, "bigger_on_smaller", read(t, ctx, player).Error.Code)
+
+	// moves made over REST are pushed too
+	call(t, srv, http.MethodPost, "/api/games/"+string(g.ID)+"/moves", moveRequest{From: 0, To: 2}, http.StatusOK, nil)
+	assert.Equal(t, uint(2), read(t, ctx, spectator).Game.Steps)
+	assert.Equal(t, uint(2), read(t, ctx, player).Game.Steps)
+
+	require.NoError(t, wsjson.Write(ctx, player, wsRequest{Type: wsMove, From: 1, To: 2}))
+	msg = read(t, ctx, spectator)
+	assert.Equal(t, wsWon, msg.Type)
+	assert.True(t, msg.Game.Won)
+	assert.Equal(t, wsWon, read(t, ctx, player).Type)
+}
+
+func TestMoveErrorCodes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	srv := newTestServer(t)
+
+	var p playerResponse
+	call(t, srv, http.MethodPost, "/api/players", playerRequest{Nickname: "anru"}, http.StatusCreated, &p)
+
+	tests := []struct {
+		ruleset string
+		moves   []moveRequest
+		bad     moveRequest
+		code    string
+	}{
+		{"standard", nil, moveRequest{From: 0, To: 0}, "illegal_move"},
+		{"cyclic", nil, moveRequest{From: 0, To: 2}, "illegal_move"},
+		{"adjacent", nil, moveRequest{From: 0, To: 2}, "illegal_move"},
+		{"magnetic", []moveRequest{{From: 0, To: 1}, {From: 0, To: 2}}, moveRequest{From: 1, To: 2}, "like_poles"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ruleset, func(t *testing.T) {
+			var g gameResponse
+			req := createGameRequest{PlayerID: p.ID, Pegs: 3, Disks: 3, Start: "classic", Goal: "peg:2", Ruleset: tt.ruleset}
+			call(t, srv, http.MethodPost, "/api/games", req, http.StatusCreated, &g)
+			for _, m := range tt.moves {
+				call(t, srv, http.MethodPost, "/api/games/"+string(g.ID)+"/moves", m, http.StatusOK, nil)
+			}
+			var res errorResponse
+			call(t, srv, http.MethodPost, "/api/games/"+string(g.ID)+"/moves", tt.bad, http.StatusUnprocessableEntity, &res)
+			assert.Equal(t, tt.code, res.Code)
+
+			conn := dial(t, ctx, srv.URL+"/api/games/"+string(g.ID)+"/ws")
+			assert.Equal(t, wsState, read(t, ctx, conn).Type)
+			require.NoError(t, wsjson.Write(ctx, conn, wsRequest{Type: wsMove, From: tt.bad.From, To: tt.bad.To}))
+			msg := read(t, ctx, conn)
+			assert.Equal(t, wsError, msg.Type)
+			assert.Equal(t, tt.code, msg.Error.Code)
+		})
+	}
+}
+
+func TestWebSocketGameNotFound(t *testing.T) {
+	srv := newTestServer(t)
+
+	_, resp, err := websocket.Dial(context.Background(), "ws"+strings.TrimPrefix(srv.URL, "http")+"/api/games/nope/ws", nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}